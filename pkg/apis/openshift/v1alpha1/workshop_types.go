@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -16,6 +17,14 @@ type WorkshopSpec struct {
 	User           UserSpec           `json:"user"`
 	Source         SourceSpec         `json:"source"`
 	Infrastructure InfrastructureSpec `json:"infrastructure"`
+	// ConfigMap in the Workshop namespace with additional CAs trusted when calling the components
+	TrustedCA TrustedCASpec `json:"trustedCA,omitempty"`
+}
+
+type TrustedCASpec struct {
+	ConfigMapName string `json:"configMapName,omitempty"`
+	// ca-bundle.crt by default
+	Key string `json:"key,omitempty"`
 }
 
 type UserSpec struct {
@@ -31,8 +40,10 @@ type SourceSpec struct {
 type InfrastructureSpec struct {
 	Che         CheSpec         `json:"che"`
 	Etherpad    EtherpadSpec    `json:"etherpad"`
+	GitServer   GitServerSpec   `json:"gitServer,omitempty"`
 	Gogs        GogsSpec        `json:"gogs"`
 	Guide       GuideSpec       `json:"guide"`
+	ImagePuller ImagePullerSpec `json:"imagePuller,omitempty"`
 	Nexus       NexusSpec       `json:"nexus"`
 	Pipeline    PipelineSpec    `json:"pipeline"`
 	Project     ProjectSpec     `json:"project"`
@@ -41,22 +52,158 @@ type InfrastructureSpec struct {
 	Workshopper WorkshopperSpec `json:"workshopper"`
 }
 
+// ImagePullerSpec pulls the images of the workspaces on every schedulable node
+// before the workshop starts, along with the additional images listed
+type ImagePullerSpec struct {
+	Enabled bool     `json:"enabled"`
+	Images  []string `json:"images,omitempty"`
+}
+
 type EtherpadSpec struct {
 	Enabled bool `json:"enabled"`
+	// OpenShift Workshop Etherpad by default
+	Title string `json:"title,omitempty"`
+	// Language of the pads, en-gb by default
+	Language string `json:"language,omitempty"`
+	// PadTemplate is the Go template of the welcome pad, rendered with .WorkshopName,
+	// .Users (each with .Username, .ProjectName and .GuideURL), .GogsURL, .NexusURL, .CheURL and .AppsHostnameSuffix
+	PadTemplate string `json:"padTemplate,omitempty"`
+	// Settings is a JSON or YAML object merged into settings.json, e.g. {"loglevel": "WARN"}
+	Settings string `json:"settings,omitempty"`
 }
 
 type GogsSpec struct {
 	Enabled bool      `json:"enabled"`
 	Image   ImageSpec `json:"image"`
+	// Repositories imported into the account of each user, spec.source.gitURL by default
+	Repositories []string `json:"repositories,omitempty"`
+	// Mirror the repositories instead of importing them once
+	Mirror bool `json:"mirror,omitempty"`
+	// Size of the Gogs data volume, 4Gi by default
+	GogsVolumeSize string `json:"gogsVolumeSize,omitempty"`
+	// Size of the PostgreSQL volume, 4Gi by default
+	PostgresqlVolumeSize string `json:"postgresqlVolumeSize,omitempty"`
+	// Expose Gogs over HTTPS
+	Ssl bool `json:"ssl,omitempty"`
+	// Name of the Gogs service and route, gogs-gogs-server by default
+	ServiceName string `json:"serviceName,omitempty"`
+}
+
+// GitServerSpec selects the git server deployed when gogs.enabled is set. The accounts
+// and repositories of the users are seeded the same way with both.
+type GitServerSpec struct {
+	// gogs (default) deploys Gogs with the Gogs Operator, gitea deploys Gitea with the workshop operator.
+	// Nothing is deployed for another value, reported by the GitServerTypeValid condition.
+	Type  string    `json:"type,omitempty"`
+	Gitea GiteaSpec `json:"gitea,omitempty"`
+}
+
+type GiteaSpec struct {
+	// docker.io/gitea/gitea:1.15.10-rootless by default
+	Image ImageSpec `json:"image,omitempty"`
+	// postgresql (default) or sqlite
+	Database string `json:"database,omitempty"`
+	// Size of the Gitea data volume, 4Gi by default
+	VolumeSize string `json:"volumeSize,omitempty"`
+	// Size of the PostgreSQL volume, 4Gi by default
+	PostgresqlVolumeSize string `json:"postgresqlVolumeSize,omitempty"`
 }
 
 type NexusSpec struct {
 	Enabled bool `json:"enabled"`
+	// Namespace of Nexus, opentlc-shared by default
+	Namespace string `json:"namespace,omitempty"`
+	// Nexus Operator image, quay.io/mcouliba/nexus-operator:v0.10 by default
+	Image ImageSpec `json:"image,omitempty"`
+	// Tag of the Nexus image, latest by default
+	NexusImageTag string `json:"nexusImageTag,omitempty"`
+	// 5Gi by default
+	VolumeSize string `json:"volumeSize,omitempty"`
+	// 1 and 2 cores by default
+	CPURequest int `json:"cpuRequest,omitempty"`
+	CPULimit   int `json:"cpuLimit,omitempty"`
+	// 2Gi by default
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+	// Repositories per format and type, each list empty uses the default repositories
+	Repositories NexusRepositoriesSpec `json:"repositories,omitempty"`
+	Warmup       NexusWarmupSpec       `json:"warmup,omitempty"`
+}
+
+// NexusWarmupSpec fills the proxy cache of Nexus once it is ready, with a Job resolving
+// the artifacts through the Maven and npm groups. The Job runs again when the list changes.
+type NexusWarmupSpec struct {
+	Enabled bool `json:"enabled"`
+	// Maven artifacts as groupId:artifactId:version[:packaging[:classifier]]
+	Maven []string `json:"maven,omitempty"`
+	// npm packages as name[@version]
+	Npm []string `json:"npm,omitempty"`
+	// Maven project built once to resolve its dependencies and plugins
+	Project *NexusWarmupProjectSpec `json:"project,omitempty"`
+	// Image resolving the Maven artifacts and building the project, with git, docker.io/library/maven:3.6-jdk-11 by default
+	MavenImage ImageSpec `json:"mavenImage,omitempty"`
+	// Image resolving the npm packages, docker.io/library/node:12 by default
+	NpmImage ImageSpec `json:"npmImage,omitempty"`
+}
+
+type NexusWarmupProjectSpec struct {
+	GitURL string `json:"gitURL"`
+	// master by default
+	GitBranch string `json:"gitBranch,omitempty"`
+	// Directory of the pom.xml in the repository
+	ContextDir string `json:"contextDir,omitempty"`
+}
+
+type NexusRepositoriesSpec struct {
+	MavenProxy   []NexusProxyRepositorySpec        `json:"mavenProxy,omitempty"`
+	MavenHosted  []NexusMavenHostedRepositorySpec  `json:"mavenHosted,omitempty"`
+	MavenGroup   []NexusGroupRepositorySpec        `json:"mavenGroup,omitempty"`
+	DockerHosted []NexusDockerHostedRepositorySpec `json:"dockerHosted,omitempty"`
+	NpmProxy     []NexusProxyRepositorySpec        `json:"npmProxy,omitempty"`
+	NpmGroup     []NexusGroupRepositorySpec        `json:"npmGroup,omitempty"`
+}
+
+type NexusProxyRepositorySpec struct {
+	Name      string `json:"name"`
+	RemoteURL string `json:"remoteURL"`
+	// Maven only, permissive by default
+	LayoutPolicy string `json:"layoutPolicy,omitempty"`
+}
+
+type NexusMavenHostedRepositorySpec struct {
+	Name string `json:"name"`
+	// release by default
+	VersionPolicy string `json:"versionPolicy,omitempty"`
+	// allow_once by default
+	WritePolicy string `json:"writePolicy,omitempty"`
+}
+
+type NexusGroupRepositorySpec struct {
+	Name        string   `json:"name"`
+	MemberRepos []string `json:"memberRepos"`
+}
+
+type NexusDockerHostedRepositorySpec struct {
+	Name      string `json:"name"`
+	HTTPPort  int    `json:"httpPort"`
+	V1Enabled bool   `json:"v1Enabled,omitempty"`
 }
 
 type PipelineSpec struct {
-	Enabled     bool            `json:"enabled"`
-	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	Enabled     bool                 `json:"enabled"`
+	OperatorHub OperatorHubSpec      `json:"operatorHub"`
+	Triggers    PipelineTriggersSpec `json:"triggers,omitempty"`
+}
+
+// PipelineTriggersSpec configures the Tekton Triggers created in the project of each user
+// when Gogs is enabled, and the webhooks registered on the repositories of the user
+type PipelineTriggersSpec struct {
+	// Go template of the TriggerTemplate, TriggerBinding and EventListener as YAML documents,
+	// rendered with .Username, .ProjectName, .PipelineName, .GogsURL, .Repositories and .AppsHostnameSuffix.
+	// Each EventListener is exposed by a route registered as webhook.
+	Template string `json:"template,omitempty"`
+	// Pipeline started by the default template, ci-pipeline by default
+	PipelineName string `json:"pipelineName,omitempty"`
 }
 
 type ProjectSpec struct {
@@ -87,8 +234,80 @@ type GuideSpec struct {
 }
 
 type CheSpec struct {
-	Enabled     bool            `json:"enabled"`
-	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	Enabled              bool             `json:"enabled"`
+	OperatorHub          OperatorHubSpec  `json:"operatorHub"`
+	ServerImage          ImageSpec        `json:"serverImage,omitempty"`
+	DevfileRegistryImage string           `json:"devfileRegistryImage,omitempty"`
+	PluginRegistryImage  string           `json:"pluginRegistryImage,omitempty"`
+	TLSSupport           bool             `json:"tlsSupport,omitempty"`
+	SelfSignedCert       bool             `json:"selfSignedCert,omitempty"`
+	Storage              CheStorageSpec   `json:"storage,omitempty"`
+	Workspace            CheWorkspaceSpec `json:"workspace,omitempty"`
+	// Secret in the Workshop namespace with the username and password of the Keycloak administrator.
+	// A random password is generated by the Che Operator when empty.
+	KeycloakAdminSecret string `json:"keycloakAdminSecret,omitempty"`
+	// How the operator gets the Che token of the users to provision their workspaces:
+	// password (default) logs in OpenShift with spec.user.password, which requires a password identity provider;
+	// keycloak provisions the users with the Keycloak admin API, which works with any identity provider
+	UserTokenSource string `json:"userTokenSource,omitempty"`
+	// Only list in status the users and workspaces outside spec.user.number instead of deleting them.
+	// Only the Keycloak users marked by the Workshop are deprovisioned, never when spec.user.number is 0.
+	DeprovisionDryRun bool `json:"deprovisionDryRun,omitempty"`
+	// Che server properties, overriding the defaults and the typed settings
+	Properties map[string]string `json:"properties,omitempty"`
+	// Devfile of the user workspaces, devfile.yaml of spec.source by default
+	Devfile CheDevfileSpec `json:"devfile,omitempty"`
+}
+
+// CheDevfileSpec defines where the devfile comes from, only one source should be set.
+// The devfile is a Go template rendered for each user with .Username, .ProjectName,
+// .GogsURL, .NexusURL, .MavenMirrorURL and .AppsHostnameSuffix. .MavenMirrorURL is the in-cluster URL
+// of the Maven group of Nexus, also given to the workspaces as MAVEN_MIRROR_URL.
+type CheDevfileSpec struct {
+	URL       string                `json:"url,omitempty"`
+	Inline    string                `json:"inline,omitempty"`
+	ConfigMap *DevfileConfigMapSpec `json:"configMap,omitempty"`
+	Git       *DevfileGitSpec       `json:"git,omitempty"`
+}
+
+// DevfileConfigMapSpec references a ConfigMap in the Workshop namespace
+type DevfileConfigMapSpec struct {
+	Name string `json:"name"`
+	// devfile.yaml by default
+	Key string `json:"key,omitempty"`
+}
+
+// DevfileGitSpec references a file of a repository hosted on GitHub, GitLab, Bitbucket, Gitea or Gogs
+type DevfileGitSpec struct {
+	URL string `json:"url"`
+	// github, gitlab, bitbucket, gitea or gogs, guessed from the host by default
+	// and taken from gitServer.type for the workshop git server
+	Type string `json:"type,omitempty"`
+	// master by default
+	Branch string `json:"branch,omitempty"`
+	// devfile.yaml by default
+	Path string `json:"path,omitempty"`
+}
+
+type CheStorageSpec struct {
+	// common, per-workspace (default) or unique
+	PvcStrategy      string `json:"pvcStrategy,omitempty"`
+	PvcClaimSize     string `json:"pvcClaimSize,omitempty"`
+	StorageClassName string `json:"storageClassName,omitempty"`
+}
+
+// CheWorkspaceSpec defines the defaults applied to the workspaces
+type CheWorkspaceSpec struct {
+	DefaultMemoryLimit   string `json:"defaultMemoryLimit,omitempty"`
+	DefaultMemoryRequest string `json:"defaultMemoryRequest,omitempty"`
+	// Running or Stopped, workspaces are left as they are when empty
+	State string `json:"state,omitempty"`
+	// Duration of inactivity after which workspaces are stopped, e.g. 30m. Never stopped when empty
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+	// Maximum RAM of a workspace, e.g. 4Gi
+	MaxRAM string `json:"maxRAM,omitempty"`
+	// Maximum number of workspaces running at once for a user
+	MaxRunningPerUser int `json:"maxRunningPerUser,omitempty"`
 }
 
 type SquashSpec struct {
@@ -98,11 +317,24 @@ type SquashSpec struct {
 type OperatorHubSpec struct {
 	Channel               string `json:"channel"`
 	ClusterServiceVersion string `json:"clusterServiceVersion"`
+	// Subscribe to the head of the channel when the pinned ClusterServiceVersion
+	// is no longer available in the catalog
+	FallbackToChannelHead bool `json:"fallbackToChannelHead,omitempty"`
+	// Automatic (default) or Manual. With Manual, the operator only approves
+	// the InstallPlan installing the pinned ClusterServiceVersion
+	InstallPlanApproval string `json:"installPlanApproval,omitempty"`
+	// Defaults to the component's OperatorHub catalog in openshift-marketplace
+	CatalogSource          string `json:"catalogSource,omitempty"`
+	CatalogSourceNamespace string `json:"catalogSourceNamespace,omitempty"`
+	// Index image of a mirrored catalog. When set, the operator creates the
+	// CatalogSource itself, catalogSource must then name it and differ from the default catalogs
+	CatalogSourceImage string `json:"catalogSourceImage,omitempty"`
 }
 
 type ImageSpec struct {
 	Name string `json:"name"`
-	Tag  string `json:"tag"`
+	// Appended to the name when set, leave empty to use a digest in the name
+	Tag string `json:"tag"`
 }
 
 // WorkshopStatus defines the observed state of Workshop
@@ -111,13 +343,143 @@ type WorkshopStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "operator-sdk generate k8s" to regenerate code after modifying this file
 	// Add custom validation using kubebuilder tags: https://book.kubebuilder.io/beyond_basics/generating_crd.html
-	Che         string `json:"che"`
-	Etherpad    string `json:"etherpad"`
-	Gogs        string `json:"gogs"`
-	Guide       string `json:"guide"`
-	Nexus       string `json:"nexus"`
-	ServiceMesh string `json:"servicemesh"`
-	Squash      string `json:"squash"`
+	Che      string `json:"che"`
+	CheURL   string `json:"cheURL,omitempty"`
+	Etherpad string `json:"etherpad"`
+	// Secret in the Workshop namespace holding the username and password of the Etherpad administrator, set once Etherpad uses it
+	EtherpadAdminSecret string             `json:"etherpadAdminSecret,omitempty"`
+	EtherpadPad         *EtherpadPadStatus `json:"etherpadPad,omitempty"`
+	Gogs                string             `json:"gogs"`
+	GogsURL             string             `json:"gogsURL,omitempty"`
+	Guide               string             `json:"guide"`
+	Nexus               string             `json:"nexus"`
+	NexusURL            string             `json:"nexusURL,omitempty"`
+	ServiceMesh         string             `json:"servicemesh"`
+	Squash              string             `json:"squash"`
+
+	Conditions []WorkshopCondition `json:"conditions,omitempty"`
+	Capacity   *CapacityStatus     `json:"capacity,omitempty"`
+	Operators  []OperatorStatus    `json:"operators,omitempty"`
+
+	CheWorkspaces []CheWorkspaceStatus `json:"cheWorkspaces,omitempty"`
+	ImagePuller   *ImagePullerStatus   `json:"imagePuller,omitempty"`
+	GogsUsers     []GogsUserStatus     `json:"gogsUsers,omitempty"`
+	// Repositories created by the Nexus Operator
+	NexusRepositories []NexusRepositoryStatus `json:"nexusRepositories,omitempty"`
+	NexusWarmup       *NexusWarmupStatus      `json:"nexusWarmup,omitempty"`
+	// Che users outside spec.user.number, being removed or only listed in dry run
+	CheDeprovision []CheDeprovisionStatus `json:"cheDeprovision,omitempty"`
+}
+
+type WorkshopConditionType string
+
+const (
+	// CapacitySufficient reports whether the cluster can hold the resources
+	// requested by the enabled components for the configured number of users
+	CapacitySufficient WorkshopConditionType = "CapacitySufficient"
+
+	// <Component>CatalogValid report whether the package, channel and pinned
+	// ClusterServiceVersion requested for the component exist in the catalog
+	CheCatalogValid         WorkshopConditionType = "CheCatalogValid"
+	PipelineCatalogValid    WorkshopConditionType = "PipelineCatalogValid"
+	ServiceMeshCatalogValid WorkshopConditionType = "ServiceMeshCatalogValid"
+
+	// GitServerTypeValid reports whether gitServer.type is gogs or gitea
+	GitServerTypeValid WorkshopConditionType = "GitServerTypeValid"
+
+	// EtherpadSettingsValid reports whether the Etherpad settings overlay and pad template can be used,
+	// the ConfigMap or the welcome pad are left as they are otherwise
+	EtherpadSettingsValid WorkshopConditionType = "EtherpadSettingsValid"
+)
+
+type WorkshopCondition struct {
+	Type               WorkshopConditionType  `json:"type"`
+	Status             corev1.ConditionStatus `json:"status"`
+	Reason             string                 `json:"reason,omitempty"`
+	Message            string                 `json:"message,omitempty"`
+	LastTransitionTime metav1.Time            `json:"lastTransitionTime,omitempty"`
+}
+
+// OperatorStatus reports the installation of a component installed by OLM
+type OperatorStatus struct {
+	Subscription     string `json:"subscription"`
+	Namespace        string `json:"namespace"`
+	CurrentCSV       string `json:"currentCSV,omitempty"`
+	InstalledCSV     string `json:"installedCSV,omitempty"`
+	Phase            string `json:"phase,omitempty"`
+	InstallPlan      string `json:"installPlan,omitempty"`
+	InstallPlanPhase string `json:"installPlanPhase,omitempty"`
+	AvailableUpgrade string `json:"availableUpgrade,omitempty"`
+	// InstallPlans waiting for a manual approval that are not on the path to the pinned ClusterServiceVersion,
+	// with the ClusterServiceVersions they install
+	PendingInstallPlans string `json:"pendingInstallPlans,omitempty"`
+	Message             string `json:"message,omitempty"`
+}
+
+// CheWorkspaceStatus reports the workspace provisioned for a user
+type CheWorkspaceStatus struct {
+	User    string `json:"user"`
+	ID      string `json:"id,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// GogsUserStatus reports the repositories imported into the Gogs account of a user
+type GogsUserStatus struct {
+	User         string   `json:"user"`
+	Repositories []string `json:"repositories,omitempty"`
+}
+
+// NexusRepositoryStatus reports the URL of a Nexus repository
+type NexusRepositoryStatus struct {
+	Name string `json:"name"`
+	// maven2, docker or npm
+	Format string `json:"format"`
+	// proxy, hosted or group
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// EtherpadPadStatus is the welcome pad listing the users and their guides
+type EtherpadPadStatus struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Checksum of the text last written to the pad, the pad is only written again when it changes
+	Checksum string `json:"checksum"`
+}
+
+// NexusWarmupStatus reports the last run of the warm-up Job
+type NexusWarmupStatus struct {
+	Completed bool   `json:"completed"`
+	Succeeded bool   `json:"succeeded"`
+	Duration  string `json:"duration,omitempty"`
+	Message   string `json:"message,omitempty"`
+}
+
+type CheDeprovisionStatus struct {
+	User       string   `json:"user"`
+	Workspaces []string `json:"workspaces,omitempty"`
+}
+
+// ImagePullerStatus reports the nodes on which the images have been pulled
+type ImagePullerStatus struct {
+	Images    []string                `json:"images,omitempty"`
+	Nodes     []ImagePullerNodeStatus `json:"nodes,omitempty"`
+	Completed bool                    `json:"completed"`
+}
+
+type ImagePullerNodeStatus struct {
+	Node   string `json:"node"`
+	Pulled bool   `json:"pulled"`
+}
+
+// CapacityStatus compares the CPU and memory requested by the workshop with the capacity of the nodes.
+// The storage of the volumes is only reported in Required.
+type CapacityStatus struct {
+	ObservedGeneration int64               `json:"observedGeneration"`
+	Required           corev1.ResourceList `json:"required,omitempty"`
+	Available          corev1.ResourceList `json:"available,omitempty"`
+	Shortfall          corev1.ResourceList `json:"shortfall,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -5,13 +5,109 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityStatus) DeepCopyInto(out *CapacityStatus) {
+	*out = *in
+	if in.Required != nil {
+		in, out := &in.Required, &out.Required
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Available != nil {
+		in, out := &in.Available, &out.Available
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Shortfall != nil {
+		in, out := &in.Shortfall, &out.Shortfall
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityStatus.
+func (in *CapacityStatus) DeepCopy() *CapacityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheDeprovisionStatus) DeepCopyInto(out *CheDeprovisionStatus) {
+	*out = *in
+	if in.Workspaces != nil {
+		in, out := &in.Workspaces, &out.Workspaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheDeprovisionStatus.
+func (in *CheDeprovisionStatus) DeepCopy() *CheDeprovisionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CheDeprovisionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheDevfileSpec) DeepCopyInto(out *CheDevfileSpec) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(DevfileConfigMapSpec)
+		**out = **in
+	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(DevfileGitSpec)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheDevfileSpec.
+func (in *CheDevfileSpec) DeepCopy() *CheDevfileSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CheDevfileSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CheSpec) DeepCopyInto(out *CheSpec) {
 	*out = *in
 	out.OperatorHub = in.OperatorHub
+	out.ServerImage = in.ServerImage
+	out.Storage = in.Storage
+	out.Workspace = in.Workspace
+	if in.Properties != nil {
+		in, out := &in.Properties, &out.Properties
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Devfile.DeepCopyInto(&out.Devfile)
 	return
 }
 
@@ -25,6 +121,102 @@ func (in *CheSpec) DeepCopy() *CheSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheStorageSpec) DeepCopyInto(out *CheStorageSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheStorageSpec.
+func (in *CheStorageSpec) DeepCopy() *CheStorageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CheStorageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheWorkspaceSpec) DeepCopyInto(out *CheWorkspaceSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheWorkspaceSpec.
+func (in *CheWorkspaceSpec) DeepCopy() *CheWorkspaceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CheWorkspaceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheWorkspaceStatus) DeepCopyInto(out *CheWorkspaceStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheWorkspaceStatus.
+func (in *CheWorkspaceStatus) DeepCopy() *CheWorkspaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CheWorkspaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevfileConfigMapSpec) DeepCopyInto(out *DevfileConfigMapSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevfileConfigMapSpec.
+func (in *DevfileConfigMapSpec) DeepCopy() *DevfileConfigMapSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DevfileConfigMapSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DevfileGitSpec) DeepCopyInto(out *DevfileGitSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DevfileGitSpec.
+func (in *DevfileGitSpec) DeepCopy() *DevfileGitSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DevfileGitSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtherpadPadStatus) DeepCopyInto(out *EtherpadPadStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtherpadPadStatus.
+func (in *EtherpadPadStatus) DeepCopy() *EtherpadPadStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtherpadPadStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EtherpadSpec) DeepCopyInto(out *EtherpadSpec) {
 	*out = *in
@@ -41,10 +233,49 @@ func (in *EtherpadSpec) DeepCopy() *EtherpadSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitServerSpec) DeepCopyInto(out *GitServerSpec) {
+	*out = *in
+	out.Gitea = in.Gitea
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitServerSpec.
+func (in *GitServerSpec) DeepCopy() *GitServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GitServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GiteaSpec) DeepCopyInto(out *GiteaSpec) {
+	*out = *in
+	out.Image = in.Image
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GiteaSpec.
+func (in *GiteaSpec) DeepCopy() *GiteaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GiteaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GogsSpec) DeepCopyInto(out *GogsSpec) {
 	*out = *in
 	out.Image = in.Image
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -58,6 +289,27 @@ func (in *GogsSpec) DeepCopy() *GogsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GogsUserStatus) DeepCopyInto(out *GogsUserStatus) {
+	*out = *in
+	if in.Repositories != nil {
+		in, out := &in.Repositories, &out.Repositories
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GogsUserStatus.
+func (in *GogsUserStatus) DeepCopy() *GogsUserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GogsUserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GuideSpec) DeepCopyInto(out *GuideSpec) {
 	*out = *in
@@ -74,6 +326,69 @@ func (in *GuideSpec) DeepCopy() *GuideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullerNodeStatus) DeepCopyInto(out *ImagePullerNodeStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullerNodeStatus.
+func (in *ImagePullerNodeStatus) DeepCopy() *ImagePullerNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullerNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullerSpec) DeepCopyInto(out *ImagePullerSpec) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullerSpec.
+func (in *ImagePullerSpec) DeepCopy() *ImagePullerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ImagePullerStatus) DeepCopyInto(out *ImagePullerStatus) {
+	*out = *in
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]ImagePullerNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ImagePullerStatus.
+func (in *ImagePullerStatus) DeepCopy() *ImagePullerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ImagePullerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 	*out = *in
@@ -93,11 +408,13 @@ func (in *ImageSpec) DeepCopy() *ImageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfrastructureSpec) DeepCopyInto(out *InfrastructureSpec) {
 	*out = *in
-	out.Che = in.Che
+	in.Che.DeepCopyInto(&out.Che)
 	out.Etherpad = in.Etherpad
-	out.Gogs = in.Gogs
+	out.GitServer = in.GitServer
+	in.Gogs.DeepCopyInto(&out.Gogs)
 	out.Guide = in.Guide
-	out.Nexus = in.Nexus
+	in.ImagePuller.DeepCopyInto(&out.ImagePuller)
+	in.Nexus.DeepCopyInto(&out.Nexus)
 	out.Pipeline = in.Pipeline
 	out.Project = in.Project
 	out.ServiceMesh = in.ServiceMesh
@@ -116,9 +433,147 @@ func (in *InfrastructureSpec) DeepCopy() *InfrastructureSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusDockerHostedRepositorySpec) DeepCopyInto(out *NexusDockerHostedRepositorySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusDockerHostedRepositorySpec.
+func (in *NexusDockerHostedRepositorySpec) DeepCopy() *NexusDockerHostedRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusDockerHostedRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusGroupRepositorySpec) DeepCopyInto(out *NexusGroupRepositorySpec) {
+	*out = *in
+	if in.MemberRepos != nil {
+		in, out := &in.MemberRepos, &out.MemberRepos
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusGroupRepositorySpec.
+func (in *NexusGroupRepositorySpec) DeepCopy() *NexusGroupRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusGroupRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusMavenHostedRepositorySpec) DeepCopyInto(out *NexusMavenHostedRepositorySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusMavenHostedRepositorySpec.
+func (in *NexusMavenHostedRepositorySpec) DeepCopy() *NexusMavenHostedRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusMavenHostedRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusProxyRepositorySpec) DeepCopyInto(out *NexusProxyRepositorySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusProxyRepositorySpec.
+func (in *NexusProxyRepositorySpec) DeepCopy() *NexusProxyRepositorySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusProxyRepositorySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusRepositoriesSpec) DeepCopyInto(out *NexusRepositoriesSpec) {
+	*out = *in
+	if in.MavenProxy != nil {
+		in, out := &in.MavenProxy, &out.MavenProxy
+		*out = make([]NexusProxyRepositorySpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.MavenHosted != nil {
+		in, out := &in.MavenHosted, &out.MavenHosted
+		*out = make([]NexusMavenHostedRepositorySpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.MavenGroup != nil {
+		in, out := &in.MavenGroup, &out.MavenGroup
+		*out = make([]NexusGroupRepositorySpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DockerHosted != nil {
+		in, out := &in.DockerHosted, &out.DockerHosted
+		*out = make([]NexusDockerHostedRepositorySpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.NpmProxy != nil {
+		in, out := &in.NpmProxy, &out.NpmProxy
+		*out = make([]NexusProxyRepositorySpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.NpmGroup != nil {
+		in, out := &in.NpmGroup, &out.NpmGroup
+		*out = make([]NexusGroupRepositorySpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusRepositoriesSpec.
+func (in *NexusRepositoriesSpec) DeepCopy() *NexusRepositoriesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusRepositoriesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusRepositoryStatus) DeepCopyInto(out *NexusRepositoryStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusRepositoryStatus.
+func (in *NexusRepositoryStatus) DeepCopy() *NexusRepositoryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusRepositoryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NexusSpec) DeepCopyInto(out *NexusSpec) {
 	*out = *in
+	out.Image = in.Image
+	in.Repositories.DeepCopyInto(&out.Repositories)
+	in.Warmup.DeepCopyInto(&out.Warmup)
 	return
 }
 
@@ -132,6 +587,71 @@ func (in *NexusSpec) DeepCopy() *NexusSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusWarmupProjectSpec) DeepCopyInto(out *NexusWarmupProjectSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusWarmupProjectSpec.
+func (in *NexusWarmupProjectSpec) DeepCopy() *NexusWarmupProjectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusWarmupProjectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusWarmupSpec) DeepCopyInto(out *NexusWarmupSpec) {
+	*out = *in
+	if in.Maven != nil {
+		in, out := &in.Maven, &out.Maven
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Npm != nil {
+		in, out := &in.Npm, &out.Npm
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Project != nil {
+		in, out := &in.Project, &out.Project
+		*out = new(NexusWarmupProjectSpec)
+		**out = **in
+	}
+	out.MavenImage = in.MavenImage
+	out.NpmImage = in.NpmImage
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusWarmupSpec.
+func (in *NexusWarmupSpec) DeepCopy() *NexusWarmupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusWarmupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NexusWarmupStatus) DeepCopyInto(out *NexusWarmupStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusWarmupStatus.
+func (in *NexusWarmupStatus) DeepCopy() *NexusWarmupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NexusWarmupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorHubSpec) DeepCopyInto(out *OperatorHubSpec) {
 	*out = *in
@@ -148,10 +668,27 @@ func (in *OperatorHubSpec) DeepCopy() *OperatorHubSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorStatus) DeepCopyInto(out *OperatorStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatus.
+func (in *OperatorStatus) DeepCopy() *OperatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 	*out = *in
 	out.OperatorHub = in.OperatorHub
+	out.Triggers = in.Triggers
 	return
 }
 
@@ -165,6 +702,22 @@ func (in *PipelineSpec) DeepCopy() *PipelineSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PipelineTriggersSpec) DeepCopyInto(out *PipelineTriggersSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineTriggersSpec.
+func (in *PipelineTriggersSpec) DeepCopy() *PipelineTriggersSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PipelineTriggersSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectSpec) DeepCopyInto(out *ProjectSpec) {
 	*out = *in
@@ -233,6 +786,22 @@ func (in *SquashSpec) DeepCopy() *SquashSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrustedCASpec) DeepCopyInto(out *TrustedCASpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrustedCASpec.
+func (in *TrustedCASpec) DeepCopy() *TrustedCASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(TrustedCASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserSpec) DeepCopyInto(out *UserSpec) {
 	*out = *in
@@ -254,8 +823,8 @@ func (in *Workshop) DeepCopyInto(out *Workshop) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -277,6 +846,23 @@ func (in *Workshop) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkshopCondition) DeepCopyInto(out *WorkshopCondition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkshopCondition.
+func (in *WorkshopCondition) DeepCopy() *WorkshopCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkshopCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkshopList) DeepCopyInto(out *WorkshopList) {
 	*out = *in
@@ -315,7 +901,8 @@ func (in *WorkshopSpec) DeepCopyInto(out *WorkshopSpec) {
 	*out = *in
 	out.User = in.User
 	out.Source = in.Source
-	out.Infrastructure = in.Infrastructure
+	in.Infrastructure.DeepCopyInto(&out.Infrastructure)
+	out.TrustedCA = in.TrustedCA
 	return
 }
 
@@ -332,6 +919,62 @@ func (in *WorkshopSpec) DeepCopy() *WorkshopSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkshopStatus) DeepCopyInto(out *WorkshopStatus) {
 	*out = *in
+	if in.EtherpadPad != nil {
+		in, out := &in.EtherpadPad, &out.EtherpadPad
+		*out = new(EtherpadPadStatus)
+		**out = **in
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]WorkshopCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(CapacityStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Operators != nil {
+		in, out := &in.Operators, &out.Operators
+		*out = make([]OperatorStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.CheWorkspaces != nil {
+		in, out := &in.CheWorkspaces, &out.CheWorkspaces
+		*out = make([]CheWorkspaceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImagePuller != nil {
+		in, out := &in.ImagePuller, &out.ImagePuller
+		*out = new(ImagePullerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GogsUsers != nil {
+		in, out := &in.GogsUsers, &out.GogsUsers
+		*out = make([]GogsUserStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NexusRepositories != nil {
+		in, out := &in.NexusRepositories, &out.NexusRepositories
+		*out = make([]NexusRepositoryStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.NexusWarmup != nil {
+		in, out := &in.NexusWarmup, &out.NexusWarmup
+		*out = new(NexusWarmupStatus)
+		**out = **in
+	}
+	if in.CheDeprovision != nil {
+		in, out := &in.CheDeprovision, &out.CheDeprovision
+		*out = make([]CheDeprovisionStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
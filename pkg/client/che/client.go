@@ -0,0 +1,124 @@
+package che
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+)
+
+// Workspace statuses reported by Che
+const (
+	WorkspaceStarting = "STARTING"
+	WorkspaceRunning  = "RUNNING"
+	WorkspaceStopping = "STOPPING"
+	WorkspaceStopped  = "STOPPED"
+)
+
+// Client calls the Che workspace API on behalf of the owner of the access token
+type Client struct {
+	*httpclient.Client
+}
+
+// Workspace is the subset of the Che workspace representation used by the operator
+type Workspace struct {
+	ID        string  `json:"id"`
+	Namespace string  `json:"namespace,omitempty"`
+	Status    string  `json:"status"`
+	Devfile   Devfile `json:"devfile"`
+}
+
+type Devfile struct {
+	Metadata DevfileMetadata `json:"metadata"`
+}
+
+type DevfileMetadata struct {
+	Name         string `json:"name,omitempty"`
+	GenerateName string `json:"generateName,omitempty"`
+}
+
+// New returns a Client for the Che server, e.g. https://che-eclipse-che.apps.cluster.example.com
+func New(baseURL string, transport http.RoundTripper) *Client {
+	return &Client{Client: httpclient.New(baseURL, transport)}
+}
+
+// ListWorkspaces returns the workspaces of the owner of the access token
+func (c *Client) ListWorkspaces(ctx context.Context, accessToken string) ([]Workspace, error) {
+	workspaces := []Workspace{}
+	request := &httpclient.Request{
+		Method: http.MethodGet,
+		Path:   "/api/workspace",
+		Query:  url.Values{"maxItems": {"100"}},
+		Header: header(accessToken),
+	}
+	if _, err := c.Do(ctx, request, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// CreateWorkspace creates a workspace in the namespace from a devfile in JSON
+func (c *Client) CreateWorkspace(ctx context.Context, accessToken string, namespace string,
+	devfile []byte, startAfterCreate bool) (*Workspace, error) {
+	workspace := &Workspace{}
+	request := &httpclient.Request{
+		Method: http.MethodPost,
+		Path:   "/api/workspace/devfile",
+		Query: url.Values{
+			"namespace":          {namespace},
+			"start-after-create": {strconv.FormatBool(startAfterCreate)},
+		},
+		Header: header(accessToken),
+		Body:   devfile,
+	}
+	if _, err := c.Do(ctx, request, workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// StartWorkspace starts the runtime of the workspace
+func (c *Client) StartWorkspace(ctx context.Context, accessToken string, id string) (*Workspace, error) {
+	workspace := &Workspace{}
+	request := &httpclient.Request{
+		Method: http.MethodPost,
+		Path:   "/api/workspace/" + url.PathEscape(id) + "/runtime",
+		Header: header(accessToken),
+	}
+	if _, err := c.Do(ctx, request, workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// StopWorkspace stops the runtime of the workspace
+func (c *Client) StopWorkspace(ctx context.Context, accessToken string, id string) error {
+	request := &httpclient.Request{
+		Method: http.MethodDelete,
+		Path:   "/api/workspace/" + url.PathEscape(id) + "/runtime",
+		Header: header(accessToken),
+	}
+	_, err := c.Do(ctx, request, nil)
+	return err
+}
+
+// DeleteWorkspace deletes a stopped workspace
+func (c *Client) DeleteWorkspace(ctx context.Context, accessToken string, id string) error {
+	request := &httpclient.Request{
+		Method: http.MethodDelete,
+		Path:   "/api/workspace/" + url.PathEscape(id),
+		Header: header(accessToken),
+	}
+	_, err := c.Do(ctx, request, nil)
+	return err
+}
+
+func header(accessToken string) http.Header {
+	return http.Header{
+		"Authorization": {"Bearer " + accessToken},
+		"Content-Type":  {"application/json"},
+		"Accept":        {"application/json"},
+	}
+}
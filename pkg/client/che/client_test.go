@@ -0,0 +1,117 @@
+package che
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+)
+
+func newTestClient(baseURL string) *Client {
+	client := New(baseURL, nil)
+	client.Backoff = time.Millisecond
+	return client
+}
+
+func TestListWorkspaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/workspace" || r.URL.Query().Get("maxItems") != "100" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		if r.Header.Get("Authorization") != "Bearer user-token" {
+			t.Errorf("unexpected Authorization %q", r.Header.Get("Authorization"))
+		}
+		w.Write([]byte(`[{"id":"workspace1","status":"RUNNING","devfile":{"metadata":{"name":"wksp"}}}]`))
+	}))
+	defer server.Close()
+
+	workspaces, err := newTestClient(server.URL).ListWorkspaces(context.TODO(), "user-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(workspaces) != 1 || workspaces[0].Status != WorkspaceRunning || workspaces[0].Devfile.Metadata.Name != "wksp" {
+		t.Errorf("unexpected workspaces %+v", workspaces)
+	}
+}
+
+func TestCreateWorkspace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/workspace/devfile" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("namespace") != "user1" || query.Get("start-after-create") != "true" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != `{"apiVersion":"1.0.0"}` {
+			t.Errorf("unexpected devfile %s", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"workspace1","status":"STARTING"}`))
+	}))
+	defer server.Close()
+
+	workspace, err := newTestClient(server.URL).CreateWorkspace(context.TODO(), "user-token", "user1", []byte(`{"apiVersion":"1.0.0"}`), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if workspace.ID != "workspace1" || workspace.Status != WorkspaceStarting {
+		t.Errorf("unexpected workspace %+v", workspace)
+	}
+}
+
+func TestCreateWorkspaceIsNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).CreateWorkspace(context.TODO(), "user-token", "user1", []byte(`{}`), true)
+	if !httpclient.IsStatus(err, http.StatusGatewayTimeout) {
+		t.Fatalf("expected a 504 error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single attempt, got %d", calls)
+	}
+}
+
+func TestStopWorkspaceRetriesUnavailableServer(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/workspace/workspace1/runtime" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := newTestClient(server.URL).StopWorkspace(context.TODO(), "user-token", "workspace1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestDeleteWorkspaceNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	if err := newTestClient(server.URL).DeleteWorkspace(context.TODO(), "user-token", "workspace1"); !httpclient.IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
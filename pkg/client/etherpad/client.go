@@ -0,0 +1,106 @@
+package etherpad
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+)
+
+// APIVersion is the version of the HTTP API served by Etherpad 1.7 and later
+const APIVersion = "1.2.13"
+
+// Client calls the Etherpad HTTP API authenticated with the key read by Etherpad from APIKEY.txt
+type Client struct {
+	*httpclient.Client
+	APIKey string
+}
+
+// Error is returned when Etherpad answers with a code other than 0,
+// e.g. 1 for wrong parameters and 4 for a wrong API key
+type Error struct {
+	Method  string
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s returned code %d: %s", e.Method, e.Code, e.Message)
+}
+
+type response struct {
+	Code    int                    `json:"code"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// New returns a Client for the Etherpad server, e.g. http://etherpad-workshop-infra.apps.cluster.example.com
+func New(baseURL string, transport http.RoundTripper, apiKey string) *Client {
+	return &Client{Client: httpclient.New(baseURL, transport), APIKey: apiKey}
+}
+
+// Ping checks that the server answers, without retrying
+func (c *Client) Ping(ctx context.Context) error {
+	client := *c.Client
+	client.Retries = 0
+	_, err := client.Do(ctx, &httpclient.Request{Method: http.MethodGet, Path: "/api"}, nil)
+	return err
+}
+
+// CreatePad creates a pad with the text, or the default pad text of the settings when empty
+func (c *Client) CreatePad(ctx context.Context, padID string, text string) error {
+	params := url.Values{"padID": {padID}}
+	if text != "" {
+		params.Set("text", text)
+	}
+	_, err := c.call(ctx, "createPad", params)
+	return err
+}
+
+// ListAllPads returns the IDs of all the pads
+func (c *Client) ListAllPads(ctx context.Context) ([]string, error) {
+	data, err := c.call(ctx, "listAllPads", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	padIDs := []string{}
+	if ids, ok := data["padIDs"].([]interface{}); ok {
+		for _, id := range ids {
+			if padID, ok := id.(string); ok {
+				padIDs = append(padIDs, padID)
+			}
+		}
+	}
+	return padIDs, nil
+}
+
+// SetText replaces the text of the pad
+func (c *Client) SetText(ctx context.Context, padID string, text string) error {
+	_, err := c.call(ctx, "setText", url.Values{"padID": {padID}, "text": {text}})
+	return err
+}
+
+// call posts the parameters as a form, the text of a pad can exceed the length of a query string
+func (c *Client) call(ctx context.Context, method string, params url.Values) (map[string]interface{}, error) {
+	params.Set("apikey", c.APIKey)
+	request := &httpclient.Request{
+		Method: http.MethodPost,
+		Path:   "/api/" + APIVersion + "/" + method,
+		Header: http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+			"Accept":       {"application/json"},
+		},
+		Body: []byte(params.Encode()),
+	}
+
+	result := &response{}
+	if _, err := c.Do(ctx, request, result); err != nil {
+		return nil, err
+	}
+	if result.Code != 0 {
+		return nil, &Error{Method: method, Code: result.Code, Message: result.Message}
+	}
+	return result.Data, nil
+}
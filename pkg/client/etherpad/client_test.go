@@ -0,0 +1,96 @@
+package etherpad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestClient(baseURL string) *Client {
+	client := New(baseURL, nil, "secret-key")
+	client.Backoff = time.Millisecond
+	return client
+}
+
+func TestCreatePad(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/"+APIVersion+"/createPad" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("expected the parameters in the body, got query %q", r.URL.RawQuery)
+		}
+		for key, expected := range map[string]string{
+			"apikey": "secret-key",
+			"padID":  "workshop",
+			"text":   "Welcome",
+		} {
+			if value := r.PostForm.Get(key); value != expected {
+				t.Errorf("expected %s=%s, got %q", key, expected, value)
+			}
+		}
+		w.Write([]byte(`{"code":0,"message":"ok","data":null}`))
+	}))
+	defer server.Close()
+
+	if err := newTestClient(server.URL).CreatePad(context.TODO(), "workshop", "Welcome"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestCreatePadWithoutText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := r.PostForm["text"]; ok {
+			t.Error("expected no text parameter so that the default pad text is used")
+		}
+		w.Write([]byte(`{"code":0,"message":"ok","data":null}`))
+	}))
+	defer server.Close()
+
+	if err := newTestClient(server.URL).CreatePad(context.TODO(), "workshop", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestListAllPads(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/"+APIVersion+"/listAllPads" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"code":0,"message":"ok","data":{"padIDs":["workshop","notes"]}}`))
+	}))
+	defer server.Close()
+
+	padIDs, err := newTestClient(server.URL).ListAllPads(context.TODO())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"workshop", "notes"}; !reflect.DeepEqual(padIDs, expected) {
+		t.Errorf("expected %v, got %v", expected, padIDs)
+	}
+}
+
+func TestCallError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":4,"message":"no or wrong API Key","data":null}`))
+	}))
+	defer server.Close()
+
+	err := newTestClient(server.URL).SetText(context.TODO(), "workshop", "Welcome")
+	etherpadError, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("expected an Etherpad error, got %v", err)
+	}
+	if etherpadError.Method != "setText" || etherpadError.Code != 4 {
+		t.Errorf("unexpected error %v", etherpadError)
+	}
+}
@@ -0,0 +1,226 @@
+package gogs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
+)
+
+// Client calls the Gogs API, also served by Gitea, authenticated with basic auth when Username is set
+type Client struct {
+	*httpclient.Client
+	Username string
+	Password string
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+type CreateUserOption struct {
+	SourceID   int64  `json:"source_id"`
+	LoginName  string `json:"login_name"`
+	Username   string `json:"username"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	SendNotify bool   `json:"send_notify"`
+	// Gitea only, true when unset
+	MustChangePassword *bool `json:"must_change_password,omitempty"`
+}
+
+type Repository struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	HTMLURL  string `json:"html_url"`
+	CloneURL string `json:"clone_url"`
+	Mirror   bool   `json:"mirror"`
+}
+
+type MigrateRepoOption struct {
+	CloneAddr string `json:"clone_addr"`
+	UID       int64  `json:"uid"`
+	RepoName  string `json:"repo_name"`
+	Mirror    bool   `json:"mirror"`
+	Private   bool   `json:"private"`
+}
+
+// Hook is a webhook of a repository
+type Hook struct {
+	ID     int64             `json:"id,omitempty"`
+	Type   string            `json:"type"`
+	Config map[string]string `json:"config"`
+	Events []string          `json:"events"`
+	Active bool              `json:"active"`
+}
+
+// New returns a Client for the Gogs server, e.g. http://gogs-gogs-server-workshop-infra.apps.cluster.example.com
+func New(baseURL string, transport http.RoundTripper) *Client {
+	return &Client{Client: httpclient.New(baseURL, transport)}
+}
+
+// WithBasicAuth returns a copy of the client authenticated as the given user
+func (c *Client) WithBasicAuth(username string, password string) *Client {
+	return &Client{Client: c.Client, Username: username, Password: password}
+}
+
+// Ping checks that the server answers, without retrying
+func (c *Client) Ping(ctx context.Context) error {
+	client := *c.Client
+	client.Retries = 0
+	_, err := client.Do(ctx, &httpclient.Request{Method: http.MethodGet, Path: "/"}, nil)
+	return err
+}
+
+// SignUp registers a user with the web form. The first user registered becomes the administrator.
+func (c *Client) SignUp(ctx context.Context, username string, email string, password string) error {
+	// The form is protected by a CSRF token bound to the session cookies
+	httpResponse, err := c.Do(ctx, &httpclient.Request{Method: http.MethodGet, Path: "/user/sign_up"}, nil)
+	if err != nil {
+		return err
+	}
+
+	cookies := []string{}
+	csrf := ""
+	for _, cookie := range httpResponse.Cookies() {
+		cookies = append(cookies, cookie.Name+"="+cookie.Value)
+		if cookie.Name == "_csrf" {
+			csrf = cookie.Value
+		}
+	}
+	if csrf == "" {
+		return fmt.Errorf("no CSRF token returned by %s/user/sign_up", c.BaseURL)
+	}
+
+	form := url.Values{
+		"_csrf":     {csrf},
+		"user_name": {username},
+		"email":     {email},
+		"password":  {password},
+		"retype":    {password},
+	}
+	request := &httpclient.Request{
+		Method: http.MethodPost,
+		Path:   "/user/sign_up",
+		Header: http.Header{
+			"Content-Type": {"application/x-www-form-urlencoded"},
+			"Cookie":       {strings.Join(cookies, "; ")},
+		},
+		Body: []byte(form.Encode()),
+		// Redirected to the login page on success, the form is rendered again with the error otherwise
+		ExpectedStatus: http.StatusFound,
+	}
+	if _, err := c.Do(ctx, request, nil); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetUser returns a user by its username
+func (c *Client) GetUser(ctx context.Context, username string) (*User, error) {
+	user := &User{}
+	if _, err := c.Do(ctx, c.newRequest(http.MethodGet, "/api/v1/users/"+url.PathEscape(username), nil), user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetAuthenticatedUser returns the user the client is authenticated as, failing with a 401 when the password is wrong
+func (c *Client) GetAuthenticatedUser(ctx context.Context) (*User, error) {
+	user := &User{}
+	if _, err := c.Do(ctx, c.newRequest(http.MethodGet, "/api/v1/user", nil), user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CreateUser creates a user, the client must be authenticated as an administrator
+func (c *Client) CreateUser(ctx context.Context, option *CreateUserOption) (*User, error) {
+	body, err := json.Marshal(option)
+	if err != nil {
+		return nil, err
+	}
+	request := c.newRequest(http.MethodPost, "/api/v1/admin/users", body)
+	request.ExpectedStatus = http.StatusCreated
+	user := &User{}
+	if _, err := c.Do(ctx, request, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetRepository returns a repository of the owner
+func (c *Client) GetRepository(ctx context.Context, owner string, name string) (*Repository, error) {
+	repository := &Repository{}
+	path := "/api/v1/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+	if _, err := c.Do(ctx, c.newRequest(http.MethodGet, path, nil), repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+// MigrateRepository imports or mirrors a remote repository for the user with id option.UID
+func (c *Client) MigrateRepository(ctx context.Context, option *MigrateRepoOption) (*Repository, error) {
+	body, err := json.Marshal(option)
+	if err != nil {
+		return nil, err
+	}
+	request := c.newRequest(http.MethodPost, "/api/v1/repos/migrate", body)
+	request.ExpectedStatus = http.StatusCreated
+	repository := &Repository{}
+	if _, err := c.Do(ctx, request, repository); err != nil {
+		return nil, err
+	}
+	return repository, nil
+}
+
+// ListHooks returns the webhooks of a repository
+func (c *Client) ListHooks(ctx context.Context, owner string, name string) ([]Hook, error) {
+	hooks := []Hook{}
+	path := "/api/v1/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name) + "/hooks"
+	if _, err := c.Do(ctx, c.newRequest(http.MethodGet, path, nil), &hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// CreateHook adds a webhook to a repository, the client must be authenticated as its owner or an administrator
+func (c *Client) CreateHook(ctx context.Context, owner string, name string, hook *Hook) (*Hook, error) {
+	body, err := json.Marshal(hook)
+	if err != nil {
+		return nil, err
+	}
+	path := "/api/v1/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name) + "/hooks"
+	request := c.newRequest(http.MethodPost, path, body)
+	request.ExpectedStatus = http.StatusCreated
+	created := &Hook{}
+	if _, err := c.Do(ctx, request, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (c *Client) newRequest(method string, path string, body []byte) *httpclient.Request {
+	header := http.Header{
+		"Content-Type": {"application/json"},
+		"Accept":       {"application/json"},
+	}
+	if c.Username != "" {
+		header.Set("Authorization", "Basic "+util.GetBasicAuth(c.Username, c.Password))
+	}
+	return &httpclient.Request{
+		Method: method,
+		Path:   path,
+		Header: header,
+		Body:   body,
+	}
+}
@@ -0,0 +1,137 @@
+package gogs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
+)
+
+func newTestClient(baseURL string) *Client {
+	client := New(baseURL, nil)
+	client.Backoff = time.Millisecond
+	return client
+}
+
+func TestGetUserNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/users/user1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if authorization := r.Header.Get("Authorization"); authorization != "" {
+			t.Errorf("expected no Authorization without credentials, got %q", authorization)
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).GetUser(context.TODO(), "user1")
+	if !httpclient.IsNotFound(err) {
+		t.Fatalf("expected a not found error, got %v", err)
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/admin/users" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if authorization := r.Header.Get("Authorization"); authorization != "Basic "+util.GetBasicAuth("gogs", "admin-password") {
+			t.Errorf("unexpected Authorization %q", authorization)
+		}
+		option := &CreateUserOption{}
+		if err := json.NewDecoder(r.Body).Decode(option); err != nil {
+			t.Fatal(err)
+		}
+		if option.Username != "user1" || option.Password != "openshift" {
+			t.Errorf("unexpected option %+v", option)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":2,"username":"user1","email":"user1@example.com"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL).WithBasicAuth("gogs", "admin-password")
+	user, err := client.CreateUser(context.TODO(), &CreateUserOption{
+		Username: "user1",
+		Email:    "user1@example.com",
+		Password: "openshift",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != 2 {
+		t.Errorf("unexpected user %+v", user)
+	}
+}
+
+func TestGetAuthenticatedUserWrongPassword(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/user" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).WithBasicAuth("gogs", "wrong").GetAuthenticatedUser(context.TODO())
+	if !httpclient.IsStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected a 401 error, got %v", err)
+	}
+}
+
+func TestSignUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user/sign_up" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			http.SetCookie(w, &http.Cookie{Name: "i_like_gogs", Value: "session"})
+			http.SetCookie(w, &http.Cookie{Name: "_csrf", Value: "token"})
+			w.Write([]byte("<html></html>"))
+		case http.MethodPost:
+			if cookie, err := r.Cookie("i_like_gogs"); err != nil || cookie.Value != "session" {
+				t.Errorf("expected the session cookie, got %v", r.Header.Get("Cookie"))
+			}
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			for key, expected := range map[string]string{
+				"_csrf":     "token",
+				"user_name": "gogs",
+				"password":  "admin-password",
+				"retype":    "admin-password",
+			} {
+				if value := r.PostForm.Get(key); value != expected {
+					t.Errorf("expected %s=%s, got %q", key, expected, value)
+				}
+			}
+			w.Header().Set("Location", "/user/login")
+			w.WriteHeader(http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	if err := newTestClient(server.URL).SignUp(context.TODO(), "gogs", "gogs@example.com", "admin-password"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSignUpRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "_csrf", Value: "token"})
+		// The form is rendered again with the error
+		w.Write([]byte("<html>Username has already been taken</html>"))
+	}))
+	defer server.Close()
+
+	if err := newTestClient(server.URL).SignUp(context.TODO(), "gogs", "gogs@example.com", "admin-password"); err == nil {
+		t.Fatal("expected an error when the form is rendered again")
+	}
+}
@@ -0,0 +1,233 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultTimeout = 30 * time.Second
+	DefaultRetries = 3
+	DefaultBackoff = time.Second
+)
+
+// Client sends requests to a base URL, retrying idempotent requests with an exponential backoff
+// when the server can not be reached or answers with a server error
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Timeout of each attempt
+	Timeout time.Duration
+	Retries int
+	Backoff time.Duration
+}
+
+// Request describes a call relative to the base URL of the Client
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+	// Status expected in the response, any 2xx status when 0
+	ExpectedStatus int
+	// Idempotent allows retrying a POST that can be sent twice safely, e.g. a token request.
+	// Other POSTs are only retried when the connection was refused.
+	Idempotent bool
+}
+
+// Error is returned when the server answers with an unexpected status
+type Error struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// IsNotFound returns whether the server answered 404
+func IsNotFound(err error) bool {
+	return IsStatus(err, http.StatusNotFound)
+}
+
+// IsStatus returns whether the server answered with the given status
+func IsStatus(err error, statusCode int) bool {
+	if e, ok := err.(*Error); ok {
+		return e.StatusCode == statusCode
+	}
+	return false
+}
+
+// New returns a Client for the base URL. A nil transport uses http.DefaultTransport.
+// Redirects are not followed so that callers can read the Location header.
+func New(baseURL string, transport http.RoundTripper) *Client {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Client{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{
+			Transport: transport,
+			// Do not follow Redirect
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Timeout: DefaultTimeout,
+		Retries: DefaultRetries,
+		Backoff: DefaultBackoff,
+	}
+}
+
+// FollowRedirects returns a copy of the client following redirects, e.g. to fetch the content of a file
+func (c *Client) FollowRedirects() *Client {
+	client := *c
+	httpClient := *c.HTTPClient
+	httpClient.CheckRedirect = nil
+	client.HTTPClient = &httpClient
+	return &client
+}
+
+// Do sends the request and decodes the JSON response into result when not nil,
+// or copies the raw response when result is a *[]byte.
+// The response is returned with its body already consumed.
+func (c *Client) Do(ctx context.Context, request *Request, result interface{}) (*http.Response, error) {
+	requestURL := c.BaseURL + request.Path
+	if len(request.Query) > 0 {
+		requestURL += "?" + request.Query.Encode()
+	}
+
+	backoff := c.Backoff
+	for attempt := 0; ; attempt++ {
+		httpResponse, body, err := c.do(ctx, request, requestURL)
+		retry := attempt < c.Retries && isRetryable(request, httpResponse, err)
+		if !retry {
+			if err != nil {
+				return nil, err
+			}
+			if !isExpectedStatus(request, httpResponse.StatusCode) {
+				return httpResponse, &Error{
+					Method:     request.Method,
+					URL:        requestURL,
+					StatusCode: httpResponse.StatusCode,
+					Body:       strings.TrimSpace(string(body)),
+				}
+			}
+			if raw, ok := result.(*[]byte); ok {
+				*raw = body
+			} else if result != nil && len(body) > 0 {
+				if err := json.Unmarshal(body, result); err != nil {
+					return httpResponse, fmt.Errorf("failed to decode the response of %s %s: %s", request.Method, requestURL, err)
+				}
+			}
+			return httpResponse, nil
+		}
+
+		if err != nil {
+			logrus.Warnf("%s %s failed, retrying in %s: %s", request.Method, requestURL, backoff, err)
+		} else {
+			logrus.Warnf("%s %s returned %d, retrying in %s", request.Method, requestURL, httpResponse.StatusCode, backoff)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) do(ctx context.Context, request *Request, requestURL string) (*http.Response, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	httpRequest, err := http.NewRequest(request.Method, requestURL, bytes.NewReader(request.Body))
+	if err != nil {
+		return nil, nil, err
+	}
+	httpRequest = httpRequest.WithContext(ctx)
+	for key, values := range request.Header {
+		for _, value := range values {
+			httpRequest.Header.Add(key, value)
+		}
+	}
+
+	httpResponse, err := c.HTTPClient.Do(httpRequest)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return httpResponse, body, nil
+}
+
+func isExpectedStatus(request *Request, statusCode int) bool {
+	if request.ExpectedStatus != 0 {
+		return statusCode == request.ExpectedStatus
+	}
+	return statusCode >= 200 && statusCode <= 299
+}
+
+// isRetryable returns whether the attempt can be sent again without creating a resource twice
+func isRetryable(request *Request, httpResponse *http.Response, err error) bool {
+	if isConnectionRefused(err) {
+		return true
+	}
+	if !isIdempotent(request) {
+		return false
+	}
+	if err != nil {
+		return isRetryableError(err)
+	}
+	return isRetryableStatus(httpResponse.StatusCode)
+}
+
+func isIdempotent(request *Request) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return request.Idempotent
+}
+
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusBadGateway ||
+		statusCode == http.StatusServiceUnavailable || statusCode == http.StatusGatewayTimeout
+}
+
+// isRetryableError returns whether the request failed before reaching the server or timed out
+func isRetryableError(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	return err == context.DeadlineExceeded
+}
+
+// isConnectionRefused returns whether the request never reached the server
+func isConnectionRefused(err error) bool {
+	return err != nil && errors.Is(err, syscall.ECONNREFUSED)
+}
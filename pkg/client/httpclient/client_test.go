@@ -0,0 +1,144 @@
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(baseURL string) *Client {
+	client := New(baseURL, nil)
+	client.Backoff = time.Millisecond
+	return client
+}
+
+func TestDoRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"name":"che"}`))
+	}))
+	defer server.Close()
+
+	result := struct {
+		Name string `json:"name"`
+	}{}
+	if _, err := newTestClient(server.URL).Do(context.TODO(), &Request{Method: http.MethodGet, Path: "/"}, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	if result.Name != "che" {
+		t.Errorf("expected the response to be decoded, got %q", result.Name)
+	}
+}
+
+func TestDoDoesNotRetryPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).Do(context.TODO(), &Request{Method: http.MethodPost, Path: "/users"}, nil)
+	if !IsStatus(err, http.StatusBadGateway) {
+		t.Fatalf("expected a 502 error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single attempt, got %d", calls)
+	}
+}
+
+func TestDoRetriesIdempotentPost(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+	}))
+	defer server.Close()
+
+	request := &Request{Method: http.MethodPost, Path: "/token", Idempotent: true}
+	if _, err := newTestClient(server.URL).Do(context.TODO(), request, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestDoRetriesPostWhenConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	client := newTestClient("http://" + address)
+	client.Retries = 2
+	start := time.Now()
+	_, err = client.Do(context.TODO(), &Request{Method: http.MethodPost, Path: "/users"}, nil)
+	if !isConnectionRefused(err) {
+		t.Fatalf("expected the connection to be refused, got %v", err)
+	}
+	// 1ms then 2ms of backoff
+	if elapsed := time.Since(start); elapsed < 3*time.Millisecond {
+		t.Errorf("expected the request to be retried, returned after %s", elapsed)
+	}
+}
+
+func TestDoReturnsErrorWithStatusAndBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such workspace", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).Do(context.TODO(), &Request{Method: http.MethodGet, Path: "/workspace/1"}, nil)
+	if !IsNotFound(err) {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+	httpErr := err.(*Error)
+	if httpErr.Body != "no such workspace" || httpErr.URL != server.URL+"/workspace/1" {
+		t.Errorf("unexpected error content: %+v", httpErr)
+	}
+}
+
+func TestDoChecksExpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	request := &Request{Method: http.MethodPost, Path: "/users", ExpectedStatus: http.StatusCreated}
+	if _, err := newTestClient(server.URL).Do(context.TODO(), request, nil); !IsStatus(err, http.StatusOK) {
+		t.Fatalf("expected an unexpected status error, got %v", err)
+	}
+}
+
+func TestDoDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/callback#access_token=abc")
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	request := &Request{Method: http.MethodGet, Path: "/oauth/authorize", ExpectedStatus: http.StatusFound}
+	httpResponse, err := newTestClient(server.URL).Do(context.TODO(), request, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if location := httpResponse.Header.Get("Location"); location != "/callback#access_token=abc" {
+		t.Errorf("unexpected Location %q", location)
+	}
+}
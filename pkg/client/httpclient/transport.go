@@ -0,0 +1,87 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NewTransport returns a transport trusting the system CAs and the given PEM bundles,
+// sending the requests through proxy. A nil proxy uses the proxy environment variables.
+func NewTransport(caBundles [][]byte, proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil || rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+	for _, caBundle := range caBundles {
+		rootCAs.AppendCertsFromPEM(caBundle)
+	}
+
+	if proxy == nil {
+		proxy = http.ProxyFromEnvironment
+	}
+
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       &tls.Config{RootCAs: rootCAs},
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// ProxyFunc returns a proxy selector following the semantics of the OpenShift cluster-wide proxy:
+// noProxy is a comma-separated list of hostnames, domains starting with a dot, IPs, CIDRs or *
+func ProxyFunc(httpProxy string, httpsProxy string, noProxy string) func(*http.Request) (*url.URL, error) {
+	return func(request *http.Request) (*url.URL, error) {
+		proxyURL := httpProxy
+		if request.URL.Scheme == "https" {
+			proxyURL = httpsProxy
+		}
+		if proxyURL == "" || isNoProxy(request.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return url.Parse(proxyURL)
+	}
+}
+
+func isNoProxy(host string, noProxy string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			return true
+		case ip != nil:
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && cidr.Contains(ip) {
+				return true
+			}
+			if entryIP := net.ParseIP(entry); entryIP != nil && entryIP.Equal(ip) {
+				return true
+			}
+		case strings.HasPrefix(entry, "."):
+			if strings.HasSuffix(host, entry) || host == entry[1:] {
+				return true
+			}
+		default:
+			if host == entry || strings.HasSuffix(host, "."+entry) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,251 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
+)
+
+// Client calls the Keycloak token endpoints and admin API
+type Client struct {
+	*httpclient.Client
+}
+
+// User is the subset of the Keycloak user representation used by the operator
+type User struct {
+	ID            string `json:"id,omitempty"`
+	Username      string `json:"username,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"emailVerified,omitempty"`
+	Enabled       bool   `json:"enabled,omitempty"`
+	// Replaced as a whole when set in an update
+	Attributes map[string][]string `json:"attributes,omitempty"`
+	// Only read by Keycloak when the user is created
+	FederatedIdentities []FederatedIdentity `json:"federatedIdentities,omitempty"`
+}
+
+// FederatedIdentity links a user to its account in an identity provider of the realm
+type FederatedIdentity struct {
+	IdentityProvider string `json:"identityProvider"`
+	UserID           string `json:"userId"`
+	UserName         string `json:"userName"`
+}
+
+// New returns a Client for the Keycloak server, e.g. https://keycloak-eclipse-che.apps.cluster.example.com
+func New(baseURL string, transport http.RoundTripper) *Client {
+	return &Client{Client: httpclient.New(baseURL, transport)}
+}
+
+// GetAdminToken returns a token of the master realm administrator
+func (c *Client) GetAdminToken(ctx context.Context, username string, password string) (*util.Token, error) {
+	return c.getToken(ctx, "master", url.Values{
+		"client_id":  {"admin-cli"},
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	})
+}
+
+// ExchangeToken exchanges a token issued by an identity provider of the realm for a token of the client
+func (c *Client) ExchangeToken(ctx context.Context, realm string, clientID string,
+	subjectToken string, subjectIssuer string) (*util.Token, error) {
+	return c.getToken(ctx, realm, url.Values{
+		"client_id":          {clientID},
+		"grant_type":         {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"subject_token":      {subjectToken},
+		"subject_issuer":     {subjectIssuer},
+		"subject_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+	})
+}
+
+// GetUserToken returns a token of a user of the realm with the password grant of the client
+func (c *Client) GetUserToken(ctx context.Context, realm string, clientID string,
+	username string, password string) (*util.Token, error) {
+	return c.getToken(ctx, realm, url.Values{
+		"client_id":  {clientID},
+		"grant_type": {"password"},
+		"username":   {username},
+		"password":   {password},
+	})
+}
+
+func (c *Client) getToken(ctx context.Context, realm string, data url.Values) (*util.Token, error) {
+	token := &util.Token{}
+	request := &httpclient.Request{
+		Method: http.MethodPost,
+		Path:   "/auth/realms/" + url.PathEscape(realm) + "/protocol/openid-connect/token",
+		Header: http.Header{"Content-Type": {"application/x-www-form-urlencoded"}},
+		Body:   []byte(data.Encode()),
+		// Requesting a token twice only issues two tokens
+		Idempotent: true,
+	}
+	if _, err := c.Do(ctx, request, token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("no access token returned by the %s realm", realm)
+	}
+	return token, nil
+}
+
+// GetUser returns the user of the realm with exactly the given username
+func (c *Client) GetUser(ctx context.Context, accessToken string, realm string, username string) (*User, error) {
+	users := []User{}
+	request := &httpclient.Request{
+		Method: http.MethodGet,
+		Path:   "/auth/admin/realms/" + url.PathEscape(realm) + "/users",
+		Query:  url.Values{"username": {username}},
+		Header: bearer(accessToken),
+	}
+	if _, err := c.Do(ctx, request, &users); err != nil {
+		return nil, err
+	}
+
+	// The username query matches substrings
+	for i := range users {
+		if users[i].Username == username {
+			return &users[i], nil
+		}
+	}
+	return nil, &httpclient.Error{
+		Method:     request.Method,
+		URL:        c.BaseURL + request.Path,
+		StatusCode: http.StatusNotFound,
+		Body:       fmt.Sprintf("user %s not found in the %s realm", username, realm),
+	}
+}
+
+// ListUsers returns all the users of the realm
+func (c *Client) ListUsers(ctx context.Context, accessToken string, realm string) ([]User, error) {
+	const pageSize = 100
+	users := []User{}
+	for first := 0; ; first += pageSize {
+		page := []User{}
+		request := &httpclient.Request{
+			Method: http.MethodGet,
+			Path:   "/auth/admin/realms/" + url.PathEscape(realm) + "/users",
+			Query:  url.Values{"first": {strconv.Itoa(first)}, "max": {strconv.Itoa(pageSize)}},
+			Header: bearer(accessToken),
+		}
+		if _, err := c.Do(ctx, request, &page); err != nil {
+			return nil, err
+		}
+		users = append(users, page...)
+		if len(page) < pageSize {
+			return users, nil
+		}
+	}
+}
+
+// DeleteUser deletes the user from the realm
+func (c *Client) DeleteUser(ctx context.Context, accessToken string, realm string, userID string) error {
+	request := &httpclient.Request{
+		Method: http.MethodDelete,
+		Path:   "/auth/admin/realms/" + url.PathEscape(realm) + "/users/" + url.PathEscape(userID),
+		Header: bearer(accessToken),
+	}
+	_, err := c.Do(ctx, request, nil)
+	return err
+}
+
+// UpdateUser updates the fields set in user
+func (c *Client) UpdateUser(ctx context.Context, accessToken string, realm string, user *User) error {
+	body, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+	request := &httpclient.Request{
+		Method: http.MethodPut,
+		Path:   "/auth/admin/realms/" + url.PathEscape(realm) + "/users/" + url.PathEscape(user.ID),
+		Header: withJSON(bearer(accessToken)),
+		Body:   body,
+	}
+	_, err = c.Do(ctx, request, nil)
+	return err
+}
+
+// CreateUser creates a user in the realm and returns it with its id
+func (c *Client) CreateUser(ctx context.Context, accessToken string, realm string, user *User) (*User, error) {
+	body, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+	request := &httpclient.Request{
+		Method:         http.MethodPost,
+		Path:           "/auth/admin/realms/" + url.PathEscape(realm) + "/users",
+		Header:         withJSON(bearer(accessToken)),
+		Body:           body,
+		ExpectedStatus: http.StatusCreated,
+	}
+	if _, err := c.Do(ctx, request, nil); err != nil {
+		return nil, err
+	}
+	return c.GetUser(ctx, accessToken, realm, user.Username)
+}
+
+// ResetPassword sets a permanent password to the user
+func (c *Client) ResetPassword(ctx context.Context, accessToken string, realm string, userID string, password string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":      "password",
+		"value":     password,
+		"temporary": false,
+	})
+	if err != nil {
+		return err
+	}
+	request := &httpclient.Request{
+		Method: http.MethodPut,
+		Path:   "/auth/admin/realms/" + url.PathEscape(realm) + "/users/" + url.PathEscape(userID) + "/reset-password",
+		Header: withJSON(bearer(accessToken)),
+		Body:   body,
+	}
+	_, err = c.Do(ctx, request, nil)
+	return err
+}
+
+// GetFederatedIdentities returns the identity provider links of the user
+func (c *Client) GetFederatedIdentities(ctx context.Context, accessToken string, realm string, userID string) ([]FederatedIdentity, error) {
+	identities := []FederatedIdentity{}
+	request := &httpclient.Request{
+		Method: http.MethodGet,
+		Path:   "/auth/admin/realms/" + url.PathEscape(realm) + "/users/" + url.PathEscape(userID) + "/federated-identity",
+		Header: bearer(accessToken),
+	}
+	if _, err := c.Do(ctx, request, &identities); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// AddFederatedIdentity links the user to its account in an identity provider
+func (c *Client) AddFederatedIdentity(ctx context.Context, accessToken string, realm string, userID string, identity *FederatedIdentity) error {
+	body, err := json.Marshal(identity)
+	if err != nil {
+		return err
+	}
+	request := &httpclient.Request{
+		Method: http.MethodPost,
+		Path: "/auth/admin/realms/" + url.PathEscape(realm) + "/users/" + url.PathEscape(userID) +
+			"/federated-identity/" + url.PathEscape(identity.IdentityProvider),
+		Header: withJSON(bearer(accessToken)),
+		Body:   body,
+	}
+	_, err = c.Do(ctx, request, nil)
+	return err
+}
+
+func bearer(accessToken string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + accessToken}}
+}
+
+func withJSON(header http.Header) http.Header {
+	header.Set("Content-Type", "application/json")
+	header.Set("Accept", "application/json")
+	return header
+}
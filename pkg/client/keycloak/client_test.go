@@ -0,0 +1,135 @@
+package keycloak
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+)
+
+func newTestClient(baseURL string) *Client {
+	client := New(baseURL, nil)
+	client.Backoff = time.Millisecond
+	return client
+}
+
+func TestExchangeToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/auth/realms/che/protocol/openid-connect/token" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		for key, expected := range map[string]string{
+			"client_id":          "che-public",
+			"grant_type":         "urn:ietf:params:oauth:grant-type:token-exchange",
+			"subject_token":      "openshift-token",
+			"subject_issuer":     "openshift-v4",
+			"subject_token_type": "urn:ietf:params:oauth:token-type:access_token",
+		} {
+			if value := r.PostForm.Get(key); value != expected {
+				t.Errorf("expected %s=%s, got %q", key, expected, value)
+			}
+		}
+		w.Write([]byte(`{"access_token":"che-token","token_type":"bearer"}`))
+	}))
+	defer server.Close()
+
+	token, err := newTestClient(server.URL).ExchangeToken(context.TODO(), "che", "che-public", "openshift-token", "openshift-v4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.AccessToken != "che-token" {
+		t.Errorf("unexpected access token %q", token.AccessToken)
+	}
+}
+
+func TestExchangeTokenRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_token"}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).ExchangeToken(context.TODO(), "che", "che-public", "expired", "openshift-v4")
+	if !httpclient.IsStatus(err, http.StatusBadRequest) {
+		t.Fatalf("expected a 400 error, got %v", err)
+	}
+}
+
+func TestGetTokenRetriesUnavailableServer(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"access_token":"admin-token"}`))
+	}))
+	defer server.Close()
+
+	token, err := newTestClient(server.URL).GetAdminToken(context.TODO(), "admin", "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.AccessToken != "admin-token" || calls != 2 {
+		t.Errorf("expected the token after 2 attempts, got %q after %d", token.AccessToken, calls)
+	}
+}
+
+func TestGetTokenWithoutAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	if _, err := newTestClient(server.URL).GetUserToken(context.TODO(), "che", "che-public", "user1", "secret"); err == nil {
+		t.Fatal("expected an error when no access token is returned")
+	}
+}
+
+func TestGetUserMatchesExactUsername(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer admin-token" {
+			t.Errorf("unexpected Authorization %q", r.Header.Get("Authorization"))
+		}
+		json.NewEncoder(w).Encode([]User{{ID: "10", Username: "user10"}, {ID: "1", Username: "user1"}})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	user, err := client.GetUser(context.TODO(), "admin-token", "che", "user1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.ID != "1" {
+		t.Errorf("expected user1, got %+v", user)
+	}
+
+	if _, err := client.GetUser(context.TODO(), "admin-token", "che", "user2"); !httpclient.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestCreateUserIsNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).CreateUser(context.TODO(), "admin-token", "che", &User{Username: "user1"})
+	if !httpclient.IsStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("expected a 503 error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single attempt, got %d", calls)
+	}
+}
@@ -0,0 +1,60 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
+)
+
+// Client requests OpenShift access tokens from the OAuth server
+type Client struct {
+	*httpclient.Client
+}
+
+// New returns a Client for the OAuth server, e.g. https://oauth-openshift.apps.cluster.example.com
+func New(baseURL string, transport http.RoundTripper) *Client {
+	return &Client{Client: httpclient.New(baseURL, transport)}
+}
+
+// GetAccessToken returns an access token for the user using the challenging client
+func (c *Client) GetAccessToken(ctx context.Context, username string, password string) (string, error) {
+	request := &httpclient.Request{
+		Method: http.MethodGet,
+		Path:   "/oauth/authorize",
+		Query: url.Values{
+			"client_id":     {"openshift-challenging-client"},
+			"response_type": {"token"},
+		},
+		Header: http.Header{
+			"Authorization": {"Basic " + util.GetBasicAuth(username, password)},
+			"X-Csrf-Token":  {"xxx"},
+		},
+		ExpectedStatus: http.StatusFound,
+	}
+
+	httpResponse, err := c.Do(ctx, request, nil)
+	if err != nil {
+		return "", err
+	}
+
+	locationURL, err := url.Parse(httpResponse.Header.Get("Location"))
+	if err != nil {
+		return "", err
+	}
+
+	fragment, err := url.ParseQuery(locationURL.Fragment)
+	if err != nil {
+		return "", err
+	}
+
+	accessToken := fragment.Get("access_token")
+	if accessToken == "" {
+		return "", fmt.Errorf("no access token returned for %s: %s", username, fragment.Get("error_description"))
+	}
+
+	return accessToken, nil
+}
@@ -0,0 +1,75 @@
+package oauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
+)
+
+func newTestClient(baseURL string) *Client {
+	client := New(baseURL, nil)
+	client.Backoff = time.Millisecond
+	return client
+}
+
+func TestGetAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/oauth/authorize" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if clientID := r.URL.Query().Get("client_id"); clientID != "openshift-challenging-client" {
+			t.Errorf("unexpected client_id %q", clientID)
+		}
+		if authorization := r.Header.Get("Authorization"); authorization != "Basic "+util.GetBasicAuth("user1", "openshift") {
+			t.Errorf("unexpected Authorization %q", authorization)
+		}
+		if r.Header.Get("X-Csrf-Token") == "" {
+			t.Error("expected an X-Csrf-Token header")
+		}
+		w.Header().Set("Location", "https://oauth-openshift.example.com/oauth/token/implicit#access_token=openshift-token&expires_in=86400&token_type=Bearer")
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	token, err := newTestClient(server.URL).GetAccessToken(context.TODO(), "user1", "openshift")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token != "openshift-token" {
+		t.Errorf("unexpected access token %q", token)
+	}
+}
+
+func TestGetAccessTokenWithoutToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://oauth-openshift.example.com/oauth/token/implicit#error=access_denied&error_description=scope+denied")
+		w.WriteHeader(http.StatusFound)
+	}))
+	defer server.Close()
+
+	if _, err := newTestClient(server.URL).GetAccessToken(context.TODO(), "user1", "openshift"); err == nil {
+		t.Fatal("expected an error without an access token in the fragment")
+	}
+}
+
+func TestGetAccessTokenBadCredentials(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := newTestClient(server.URL).GetAccessToken(context.TODO(), "user1", "wrong")
+	if !httpclient.IsStatus(err, http.StatusUnauthorized) {
+		t.Fatalf("expected a 401 error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected a single request for bad credentials, got %d", requests)
+	}
+}
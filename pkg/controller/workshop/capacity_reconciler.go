@@ -0,0 +1,271 @@
+package workshop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Expected CPU and memory requests of each component, either shared by the workshop or per user.
+// The storage of their volumes is added from the spec, it is only reported as StorageClasses
+// do not report their capacity.
+var (
+	guidePerUserRequests = newResourceList("", "512Mi")
+	chePerUserRequests   = newResourceList("500m", "1Gi")
+	cheRequests          = newResourceList("1", "1536Mi")
+	etherpadRequests     = newResourceList("", "1Gi")
+	gogsRequests         = newResourceList("500m", "1Gi")
+	nexusRequests        = newResourceList("1", "2Gi")
+	pipelineRequests     = newResourceList("200m", "512Mi")
+	serviceMeshRequests  = newResourceList("2", "4Gi")
+	squashRequests       = newResourceList("100m", "128Mi")
+)
+
+// Reconciling Capacity
+func (r *ReconcileWorkshop) reconcileCapacity(instance *openshiftv1alpha1.Workshop, users int) error {
+
+	// Only estimate once per spec change, before the per-user resources are created
+	if instance.Status.Capacity != nil && instance.Status.Capacity.ObservedGeneration == instance.Generation {
+		return nil
+	}
+
+	required := estimateRequests(instance, users)
+
+	available, err := r.getAvailableCapacity(instance, users)
+	if err != nil {
+		return err
+	}
+
+	// Storage is only reported as required
+	shortfall := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		requiredQuantity := required[name]
+		availableQuantity := available[name]
+		if requiredQuantity.Cmp(availableQuantity) > 0 {
+			missing := requiredQuantity.DeepCopy()
+			missing.Sub(availableQuantity)
+			shortfall[name] = missing
+		}
+	}
+
+	instance.Status.Capacity = &openshiftv1alpha1.CapacityStatus{
+		ObservedGeneration: instance.Generation,
+		Required:           required,
+		Available:          available,
+		Shortfall:          shortfall,
+	}
+
+	if len(shortfall) > 0 {
+		message := fmt.Sprintf("Cluster is short of %s to host %d users (requires %s, available %s, storage not checked)",
+			formatResourceList(shortfall), users, formatResourceList(required), formatResourceList(available))
+		logrus.Warn(message)
+		setCondition(instance, openshiftv1alpha1.CapacitySufficient, corev1.ConditionFalse, "InsufficientCapacity", message)
+	} else {
+		message := fmt.Sprintf("Cluster can host %d users (requires %s, available %s, storage not checked)",
+			users, formatResourceList(required), formatResourceList(available))
+		logrus.Info(message)
+		setCondition(instance, openshiftv1alpha1.CapacitySufficient, corev1.ConditionTrue, "SufficientCapacity", message)
+	}
+
+	return r.updateStatus(instance)
+}
+
+// estimateRequests sums the expected requests of the enabled components for the given number of users
+func estimateRequests(instance *openshiftv1alpha1.Workshop, users int) corev1.ResourceList {
+	infrastructure := instance.Spec.Infrastructure
+	required := corev1.ResourceList{}
+
+	if infrastructure.Workshopper.Enabled {
+		addResourceList(required, guidePerUserRequests, users)
+	}
+	if infrastructure.Che.Enabled {
+		addResourceList(required, cheRequests, 1)
+		addResourceList(required, chePerUserRequests, users)
+		// PostgreSQL of Che, and one workspace per user
+		addStorage(required, "", "1Gi", 1)
+		addStorage(required, infrastructure.Che.Storage.PvcClaimSize, "1Gi", users)
+	}
+	if infrastructure.Etherpad.Enabled {
+		addResourceList(required, etherpadRequests, 1)
+		addStorage(required, "", "512Mi", 1)
+	}
+	if infrastructure.Gogs.Enabled {
+		addResourceList(required, gogsRequests, 1)
+		if isGitea(instance) {
+			addStorage(required, infrastructure.GitServer.Gitea.VolumeSize, "4Gi", 1)
+			if infrastructure.GitServer.Gitea.Database != "sqlite" {
+				addStorage(required, infrastructure.GitServer.Gitea.PostgresqlVolumeSize, "4Gi", 1)
+			}
+		} else {
+			addStorage(required, infrastructure.Gogs.GogsVolumeSize, "4Gi", 1)
+			addStorage(required, infrastructure.Gogs.PostgresqlVolumeSize, "4Gi", 1)
+		}
+	}
+	if infrastructure.Nexus.Enabled {
+		addResourceList(required, nexusRequests, 1)
+		addStorage(required, infrastructure.Nexus.VolumeSize, "5Gi", 1)
+	}
+	if infrastructure.Pipeline.Enabled {
+		addResourceList(required, pipelineRequests, 1)
+	}
+	if infrastructure.ServiceMesh.Enabled {
+		addResourceList(required, serviceMeshRequests, 1)
+	}
+	if infrastructure.Squash.Enabled {
+		addResourceList(required, squashRequests, 1)
+	}
+
+	return required
+}
+
+// getAvailableCapacity returns the allocatable CPU and memory of the schedulable nodes
+// minus what is already requested by pods not managed by the workshop, which are already part of the estimate
+func (r *ReconcileWorkshop) getAvailableCapacity(instance *openshiftv1alpha1.Workshop, users int) (corev1.ResourceList, error) {
+	nodeList := &corev1.NodeList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, nodeList); err != nil {
+		logrus.Errorf("Failed to list nodes: %s", err)
+		return nil, err
+	}
+
+	available := corev1.ResourceList{}
+	schedulableNodes := map[string]bool{}
+	for _, node := range nodeList.Items {
+		if !isNodeSchedulable(node) {
+			continue
+		}
+		schedulableNodes[node.Name] = true
+		addResourceList(available, corev1.ResourceList{
+			corev1.ResourceCPU:    node.Status.Allocatable[corev1.ResourceCPU],
+			corev1.ResourceMemory: node.Status.Allocatable[corev1.ResourceMemory],
+		}, 1)
+	}
+
+	podList := &corev1.PodList{}
+	if err := r.client.List(context.TODO(), &client.ListOptions{}, podList); err != nil {
+		logrus.Errorf("Failed to list pods: %s", err)
+		return nil, err
+	}
+
+	workshopNamespaces := getWorkshopNamespaces(instance, users)
+
+	requested := corev1.ResourceList{}
+	for _, pod := range podList.Items {
+		if !schedulableNodes[pod.Spec.NodeName] || isWorkshopPod(pod, workshopNamespaces) ||
+			pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			addResourceList(requested, corev1.ResourceList{
+				corev1.ResourceCPU:    container.Resources.Requests[corev1.ResourceCPU],
+				corev1.ResourceMemory: container.Resources.Requests[corev1.ResourceMemory],
+			}, 1)
+		}
+	}
+
+	for name, quantity := range requested {
+		availableQuantity := available[name]
+		availableQuantity.Sub(quantity)
+		available[name] = availableQuantity
+	}
+
+	return available, nil
+}
+
+// getWorkshopNamespaces returns the namespaces where the workshop and the operators it installs run pods
+func getWorkshopNamespaces(instance *openshiftv1alpha1.Workshop, users int) map[string]bool {
+	namespaces := map[string]bool{
+		instance.Namespace:          true,
+		"eclipse-che":               true,
+		getNexusNamespace(instance): true,
+		"istio-system":              true,
+		"squash-debugger":           true,
+	}
+	for id := 1; id <= users; id++ {
+		namespaces[fmt.Sprintf("infra%d", id)] = true
+		namespaces[fmt.Sprintf("%s%d", instance.Spec.Infrastructure.Project.Name, id)] = true
+	}
+	return namespaces
+}
+
+// isWorkshopPod returns whether the pod is created by the workshop, directly or through
+// the operators and Che workspaces it provisions
+func isWorkshopPod(pod corev1.Pod, workshopNamespaces map[string]bool) bool {
+	if workshopNamespaces[pod.Namespace] {
+		return true
+	}
+	if pod.Labels["app"] == "openshift-workshop" {
+		return true
+	}
+	// Workspaces run in the namespace of each Che user
+	_, workspace := pod.Labels["che.workspace_id"]
+	return workspace
+}
+
+func isNodeSchedulable(node corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return false
+	}
+	for _, taint := range node.Spec.Taints {
+		if taint.Effect == corev1.TaintEffectNoSchedule || taint.Effect == corev1.TaintEffectNoExecute {
+			return false
+		}
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func newResourceList(cpu string, memory string) corev1.ResourceList {
+	resources := corev1.ResourceList{}
+	if cpu != "" {
+		resources[corev1.ResourceCPU] = resource.MustParse(cpu)
+	}
+	if memory != "" {
+		resources[corev1.ResourceMemory] = resource.MustParse(memory)
+	}
+	return resources
+}
+
+// addStorage adds count times the size of a volume to total, or its default size when unset.
+// An invalid size is left to the creation of the volume to report.
+func addStorage(total corev1.ResourceList, size string, defaultSize string, count int) {
+	if size == "" {
+		size = defaultSize
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		logrus.Warnf("Failed to estimate the storage of a %s volume: %s", size, err)
+		return
+	}
+	addResourceList(total, corev1.ResourceList{corev1.ResourceStorage: quantity}, count)
+}
+
+// addResourceList adds count times the quantities of toAdd to total
+func addResourceList(total corev1.ResourceList, toAdd corev1.ResourceList, count int) {
+	for name, quantity := range toAdd {
+		sum := total[name]
+		for i := 0; i < count; i++ {
+			sum.Add(quantity)
+		}
+		total[name] = sum
+	}
+}
+
+func formatResourceList(resources corev1.ResourceList) string {
+	values := []string{}
+	for name, quantity := range resources {
+		values = append(values, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(values)
+	return strings.Join(values, ", ")
+}
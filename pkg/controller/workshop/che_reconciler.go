@@ -1,36 +1,47 @@
 package workshop
 
 import (
+	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
-	"net/url"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"text/template"
 	"time"
 
 	"github.com/ghodss/yaml"
 	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	checlient "github.com/redhat/openshift-workshop-operator/pkg/client/che"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/keycloak"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/oauth"
 	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
 	che "github.com/redhat/openshift-workshop-operator/pkg/deployment/che"
+	"github.com/redhat/openshift-workshop-operator/pkg/deployment/openshiftuser"
 	"github.com/redhat/openshift-workshop-operator/pkg/util"
 	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// rolloutPendingAnnotation marks a ConfigMap read on start when its Deployment has to be restarted to apply it
+const rolloutPendingAnnotation = "openshift.workshop/rollout-pending"
+
 // Reconciling Che
 func (r *ReconcileWorkshop) reconcileChe(instance *openshiftv1alpha1.Workshop, users int,
 	appsHostnameSuffix string, openshiftConsoleURL string, openshiftAPIURL string) (reconcile.Result, error) {
 	enabledChe := instance.Spec.Infrastructure.Che.Enabled
 
 	if enabledChe {
-		if result, err := r.addChe(instance, users, appsHostnameSuffix, openshiftConsoleURL, openshiftAPIURL); err != nil {
-			return result, err
-		}
+		return r.addChe(instance, users, appsHostnameSuffix, openshiftConsoleURL, openshiftAPIURL)
 	}
 
 	//Success
@@ -40,8 +51,6 @@ func (r *ReconcileWorkshop) reconcileChe(instance *openshiftv1alpha1.Workshop, u
 func (r *ReconcileWorkshop) addChe(instance *openshiftv1alpha1.Workshop, users int,
 	appsHostnameSuffix string, openshiftConsoleURL string, openshiftAPIURL string) (reconcile.Result, error) {
 
-	cheClusterServiceVersion := instance.Spec.Infrastructure.Che.OperatorHub.ClusterServiceVersion
-
 	cheNamespace := deployment.NewNamespace(instance, "eclipse-che")
 	if err := r.client.Create(context.TODO(), cheNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
@@ -63,13 +72,13 @@ func (r *ReconcileWorkshop) addChe(instance *openshiftv1alpha1.Workshop, users i
 		logrus.Infof("Created %s OperatorGroup", cheOperatorGroup.Name)
 	}
 
-	cheSubscription := deployment.NewCommunitySubscription(instance, "eclipse-che", cheNamespace.Name, "eclipse-che",
-		instance.Spec.Infrastructure.Che.OperatorHub.Channel,
-		cheClusterServiceVersion)
-	if err := r.client.Create(context.TODO(), cheSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	cheSubscription, err := r.newSubscription(instance, openshiftv1alpha1.CheCatalogValid, "eclipse-che", cheNamespace.Name, "eclipse-che",
+		"community-operators", instance.Spec.Infrastructure.Che.OperatorHub)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.addUpdateSubscription(cheSubscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		logrus.Infof("Created %s Subscription", cheSubscription.Name)
 	}
 
 	// FIX - Workspaces fail to start with certain configurations of StorageClass
@@ -81,327 +90,876 @@ func (r *ReconcileWorkshop) addChe(instance *openshiftv1alpha1.Workshop, users i
 		"CHE_WORKSPACE_AGENT_DEV_INACTIVE__STOP__TIMEOUT__MS":   "-1",
 		"CHE_WORKSPACE_AUTO_START":                              "true",
 	}
+	workspaceProperties, err := getCheWorkspaceProperties(instance.Spec.Infrastructure.Che.Workspace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	for key, value := range workspaceProperties {
+		configMapData[key] = value
+	}
+	for key, value := range instance.Spec.Infrastructure.Che.Properties {
+		configMapData[key] = value
+	}
+
 	workspacesCustomConfigMap := deployment.NewConfigMap(instance, "custom", cheNamespace.Name, configMapData)
 	if err := r.client.Create(context.TODO(), workspacesCustomConfigMap); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created Che Custom ConfigMap")
+	} else if foundConfigMap := r.GetEffectiveConfigMap(instance, "custom", cheNamespace.Name); foundConfigMap != nil &&
+		!reflect.DeepEqual(foundConfigMap.Data, configMapData) {
+		foundConfigMap.Data = configMapData
+		// Che reads its properties on start
+		if foundConfigMap.Annotations == nil {
+			foundConfigMap.Annotations = map[string]string{}
+		}
+		foundConfigMap.Annotations[rolloutPendingAnnotation] = "true"
+		if err := r.client.Update(context.TODO(), foundConfigMap); err != nil {
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Updated Che Custom ConfigMap")
 	}
 
-	var (
-		timeout = 120
-	)
-
-	// Wait for Che Operator to be running
-	time.Sleep(time.Duration(1) * time.Second)
-	cheOperatorDeployment, err := r.GetEffectiveDeployment(instance, "che-operator", cheNamespace.Name)
-	if err != nil {
-		logrus.Infof("Waiting for OLM to create che-operator deployment (%v seconds)", timeout)
-		time.Sleep(time.Duration(timeout) * time.Second)
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * time.Duration(timeout)}, err
+	// Wait for Che Operator to be installed
+	if installed, err := r.isOperatorInstalled(instance, cheSubscription); err != nil {
+		return reconcile.Result{}, err
+	} else if !installed {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
 	}
 
-	if cheOperatorDeployment.Status.AvailableReplicas != 1 {
-		scaled := k8sclient.GetDeploymentStatus("che-operator", cheNamespace.Name)
-		if !scaled {
-			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, err
+	cheCustomResource := che.NewCustomResource(instance, "eclipse-che", cheNamespace.Name)
+	if secretName := instance.Spec.Infrastructure.Che.KeycloakAdminSecret; secretName != "" {
+		secret := &corev1.Secret{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: secretName, Namespace: instance.Namespace}, secret); err != nil {
+			logrus.Errorf("Failed to get %s secret: %s", secretName, err)
+			return reconcile.Result{}, err
+		}
+		if len(secret.Data["password"]) == 0 {
+			return reconcile.Result{}, fmt.Errorf("secret %s has no password key", secretName)
 		}
+		cheCustomResource.Spec.Auth.KeycloakAdminUserName = string(secret.Data["username"])
+		cheCustomResource.Spec.Auth.KeycloakAdminPassword = string(secret.Data["password"])
 	}
-
-	cheCustomResource := che.NewCustomResource(instance, "eclipse-che", cheNamespace.Name)
 	if err := r.client.Create(context.TODO(), cheCustomResource); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created %s Custom Resource", cheCustomResource.Name)
 	}
 
-	// Wait for Che to be running
-	cheDeployment, err := r.GetEffectiveDeployment(instance, "che", cheNamespace.Name)
+	cheCluster, err := r.GetEffectiveCheCluster(instance, cheCustomResource.Name, cheNamespace.Name)
 	if err != nil {
-		logrus.Infof("Waiting for Che Operator to build resources (%v seconds)", timeout)
-		time.Sleep(time.Duration(timeout) * time.Second)
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, err
+		return reconcile.Result{}, err
 	}
 
-	if cheDeployment.Status.AvailableReplicas != 1 {
-		scaled := k8sclient.GetDeploymentStatus("che", cheNamespace.Name)
-		if !scaled {
-			return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, err
+	if che.UpdateCustomResource(cheCluster, cheCustomResource) {
+		if err := r.client.Update(context.TODO(), cheCluster); err != nil {
+			return reconcile.Result{}, err
 		}
+		logrus.Infof("Updated %s Custom Resource", cheCluster.Name)
+	}
+
+	// Report the state published by the Che Operator
+	if instance.Status.Che != cheCluster.Status.CheClusterRunning || instance.Status.CheURL != cheCluster.Status.CheURL {
+		instance.Status.Che = cheCluster.Status.CheClusterRunning
+		instance.Status.CheURL = cheCluster.Status.CheURL
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if err := r.rolloutCheServer(instance, cheNamespace.Name); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Wait for Che to be running
+	if !strings.HasPrefix(cheCluster.Status.CheClusterRunning, "Available") || cheCluster.Status.CheURL == "" {
+		logrus.Infof("Waiting for Che to be available")
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	// The Nexus settings are only given to the workspaces when they are created
+	if instance.Spec.Infrastructure.Nexus.Enabled && instance.Status.Nexus != "Available" {
+		logrus.Infof("Waiting for Nexus to create the workspaces")
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	transport, err := r.newHTTPTransport(instance)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
 	// Initialize Workspaces from devfile
-	devfile, result, err := getDevFile(instance)
+	devfileTemplate, err := r.getDevfileTemplate(instance, transport)
 	if err != nil {
-		return result, err
+		return reconcile.Result{}, err
+	}
+
+	cheURL, err := r.getComponentURL("che", cheNamespace.Name, cheCluster.Status.CheURL)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	keycloakURL, err := r.getComponentURL("keycloak", cheNamespace.Name, cheCluster.Status.KeycloakURL)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	var (
+		ctx            = context.TODO()
+		oauthClient    = oauth.New("https://oauth-openshift."+appsHostnameSuffix, transport)
+		keycloakClient = keycloak.New(keycloakURL, transport)
+		cheClient      = checlient.New(cheURL, transport)
+	)
+
+	// Credentials either provided or generated by the Che Operator when the CheCluster was created
+	keycloakAdminUserName := cheCluster.Spec.Auth.KeycloakAdminUserName
+	if keycloakAdminUserName == "" {
+		keycloakAdminUserName = "admin"
+	}
+	keycloakAdminPassword := cheCluster.Spec.Auth.KeycloakAdminPassword
+	if keycloakAdminPassword == "" {
+		logrus.Infof("Waiting for the Che Operator to generate the Keycloak admin password")
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	adminToken, err := keycloakClient.GetAdminToken(ctx, keycloakAdminUserName, keycloakAdminPassword)
+	if err != nil {
+		logrus.Errorf("Error when getting the master token from che keycloak: %v", err)
+		return reconcile.Result{}, err
+	}
+
+	gogsURL, err := r.getGogsURL(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Point the build tools of the workspaces at Nexus, through the service like the devfile
+	mavenMirrorURL := getNexusServiceRepositoryURL(instance, "maven2")
+	nexusEnv := []corev1.EnvVar{}
+	if mavenMirrorURL != "" {
+		nexusEnv = append(nexusEnv, corev1.EnvVar{Name: "MAVEN_MIRROR_URL", Value: mavenMirrorURL})
+	}
+	if npmRegistryURL := getNexusServiceRepositoryURL(instance, "npm"); npmRegistryURL != "" {
+		nexusEnv = append(nexusEnv, corev1.EnvVar{Name: "NPM_CONFIG_REGISTRY", Value: npmRegistryURL})
+	}
+
+	passwords, err := r.getCheUserPasswords(instance)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
 
+	workspacesStatus := []openshiftv1alpha1.CheWorkspaceStatus{}
+	var workspaceErr error
+	waitingForLogin := false
 	for id := 1; id <= users; id++ {
 		username := fmt.Sprintf("user%d", id)
+		workspaceStatus := openshiftv1alpha1.CheWorkspaceStatus{User: username}
 
-		userAccessToken, result, err := getUserToken(instance, username, cheNamespace.Name, appsHostnameSuffix)
+		var userAccessToken string
+		linked := true
+		if instance.Spec.Infrastructure.Che.UserTokenSource == "keycloak" {
+			userAccessToken, linked, err = r.getKeycloakUserToken(ctx, instance, keycloakClient, adminToken.AccessToken, username, passwords)
+		} else {
+			userAccessToken, err = getUserToken(ctx, oauthClient, keycloakClient, username, instance.Spec.User.Password)
+		}
+		if err == nil {
+			err = updateProvisionedUser(ctx, instance, keycloakClient, adminToken.AccessToken, username)
+		}
 		if err != nil {
-			return result, err
+			// Keep provisioning the other users and report the failure
+			workspaceStatus.Message = err.Error()
+			workspaceErr = err
+			workspacesStatus = append(workspacesStatus, workspaceStatus)
+			continue
+		}
+		if !linked {
+			workspaceStatus.Message = "Waiting for the first login of " + username + " to OpenShift to link its account"
+			waitingForLogin = true
 		}
 
-		if result, err := updateUserEmail(instance, username, cheNamespace.Name, appsHostnameSuffix); err != nil {
-			return result, err
+		devfile, err := renderDevfile(devfileTemplate, cheDevfileData{
+			Username:           username,
+			ProjectName:        fmt.Sprintf("%s%d", instance.Spec.Infrastructure.Project.Name, id),
+			GogsURL:            gogsURL,
+			NexusURL:           instance.Status.NexusURL,
+			MavenMirrorURL:     mavenMirrorURL,
+			AppsHostnameSuffix: appsHostnameSuffix,
+		})
+		if err != nil {
+			return reconcile.Result{}, err
 		}
 
-		if result, err := initWorkspace(instance, username, userAccessToken, devfile, appsHostnameSuffix); err != nil {
-			return result, err
+		devfile, err = injectDevfileEnv(devfile, nexusEnv)
+		if err != nil {
+			return reconcile.Result{}, err
 		}
+
+		if workspace, err := reconcileWorkspace(ctx, instance, cheClient, username, userAccessToken, devfile); err != nil {
+			// Keep provisioning the other users and report the failure
+			workspaceStatus.Message = err.Error()
+			workspaceErr = err
+		} else {
+			workspaceStatus.ID = workspace.ID
+			workspaceStatus.Status = workspace.Status
+		}
+		workspacesStatus = append(workspacesStatus, workspaceStatus)
+	}
+
+	if err := r.saveCheUserPasswords(passwords); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !reflect.DeepEqual(instance.Status.CheWorkspaces, workspacesStatus) {
+		instance.Status.CheWorkspaces = workspacesStatus
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if workspaceErr != nil {
+		return reconcile.Result{}, workspaceErr
+	}
+
+	if result, err := r.deprovisionCheUsers(ctx, instance, keycloakClient, cheClient, adminToken.AccessToken, users, passwords); err != nil || result.Requeue {
+		return result, err
+	}
+
+	// The OpenShift User of an attendee is only created on the first login, link it as soon as it exists
+	if waitingForLogin {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func getDevFile(instance *openshiftv1alpha1.Workshop) (string, reconcile.Result, error) {
+func getCheWorkspaceProperties(workspace openshiftv1alpha1.CheWorkspaceSpec) (map[string]string, error) {
+	properties := map[string]string{}
 
-	var (
-		httpResponse *http.Response
-		httpRequest  *http.Request
-		devfile      string
-		client       = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
+	for key, value := range map[string]string{
+		"CHE_WORKSPACE_DEFAULT__MEMORY__LIMIT__MB":   workspace.DefaultMemoryLimit,
+		"CHE_WORKSPACE_DEFAULT__MEMORY__REQUEST__MB": workspace.DefaultMemoryRequest,
+		"CHE_LIMITS_WORKSPACE_ENV_RAM":               workspace.MaxRAM,
+	} {
+		if value == "" {
+			continue
 		}
-	)
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			logrus.Errorf("Invalid Che workspace memory %s: %s", value, err)
+			return nil, err
+		}
+		properties[key] = strconv.FormatInt(quantity.Value()/(1024*1024), 10)
+	}
+	if ram, ok := properties["CHE_LIMITS_WORKSPACE_ENV_RAM"]; ok {
+		properties["CHE_LIMITS_WORKSPACE_ENV_RAM"] = ram + "mb"
+	}
 
-	gitURL, err := url.Parse(instance.Spec.Source.GitURL)
-	if err != nil {
-		return "", reconcile.Result{}, err
+	if workspace.IdleTimeout != "" {
+		idleTimeout, err := time.ParseDuration(workspace.IdleTimeout)
+		if err != nil {
+			logrus.Errorf("Invalid Che workspace idle timeout %s: %s", workspace.IdleTimeout, err)
+			return nil, err
+		}
+		properties["CHE_WORKSPACE_AGENT_DEV_INACTIVE__STOP__TIMEOUT__MS"] = strconv.FormatInt(int64(idleTimeout/time.Millisecond), 10)
 	}
-	devfileRawURL := fmt.Sprintf("https://raw.githubusercontent.com%s/%s/devfile.yaml", gitURL.Path, instance.Spec.Source.GitBranch)
-	httpRequest, err = http.NewRequest("GET", devfileRawURL, nil)
 
-	httpResponse, err = client.Do(httpRequest)
-	if err != nil {
-		logrus.Errorf("Error when getting Devfile from %s", devfileRawURL)
-		return "", reconcile.Result{}, err
+	if workspace.MaxRunningPerUser > 0 {
+		properties["CHE_LIMITS_USER_WORKSPACES_RUN_COUNT"] = strconv.Itoa(workspace.MaxRunningPerUser)
 	}
 
-	if httpResponse.StatusCode == http.StatusOK {
-		bodyBytes, err := ioutil.ReadAll(httpResponse.Body)
+	return properties, nil
+}
+
+// rolloutCheServer restarts the Che server when its properties changed since it started
+func (r *ReconcileWorkshop) rolloutCheServer(instance *openshiftv1alpha1.Workshop, cheNamespace string) error {
+	customConfigMap := r.GetEffectiveConfigMap(instance, "custom", cheNamespace)
+	if customConfigMap == nil || customConfigMap.Annotations[rolloutPendingAnnotation] != "true" {
+		return nil
+	}
+
+	// Not deployed yet otherwise, the properties will be read on start
+	cheDeployment := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "che", Namespace: cheNamespace}, cheDeployment); err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil {
+		if cheDeployment.Spec.Template.Annotations == nil {
+			cheDeployment.Spec.Template.Annotations = map[string]string{}
+		}
+		cheDeployment.Spec.Template.Annotations["openshift.workshop/restartedAt"] = time.Now().Format(time.RFC3339)
+		if err := r.client.Update(context.TODO(), cheDeployment); err != nil {
+			return err
+		}
+		logrus.Infof("Rolled out %s Deployment to apply the Che properties", cheDeployment.Name)
+	}
+
+	delete(customConfigMap.Annotations, rolloutPendingAnnotation)
+	return r.client.Update(context.TODO(), customConfigMap)
+}
+
+// cheDevfileData is passed to the devfile template of each user
+type cheDevfileData struct {
+	Username           string
+	ProjectName        string
+	GogsURL            string
+	NexusURL           string
+	MavenMirrorURL     string
+	AppsHostnameSuffix string
+}
+
+// getDevfileTemplate returns the devfile template from the source set in the spec
+func (r *ReconcileWorkshop) getDevfileTemplate(instance *openshiftv1alpha1.Workshop, transport http.RoundTripper) (string, error) {
+	devfileSpec := instance.Spec.Infrastructure.Che.Devfile
+
+	if devfileSpec.Inline != "" {
+		return devfileSpec.Inline, nil
+	}
+
+	if devfileSpec.ConfigMap != nil {
+		key := devfileSpec.ConfigMap.Key
+		if key == "" {
+			key = "devfile.yaml"
+		}
+		devfile, err := r.getConfigMapKey(devfileSpec.ConfigMap.Name, instance.Namespace, key)
 		if err != nil {
-			logrus.Errorf("Error when reading %s", devfileRawURL)
-			return "", reconcile.Result{}, err
+			return "", err
+		}
+		if devfile == nil {
+			return "", fmt.Errorf("devfile ConfigMap %s has no %s key", devfileSpec.ConfigMap.Name, key)
 		}
+		return string(devfile), nil
+	}
 
-		bodyJSON, err := yaml.YAMLToJSON(bodyBytes)
+	devfileURL := devfileSpec.URL
+	if devfileURL == "" {
+		gitSpec := openshiftv1alpha1.DevfileGitSpec{
+			URL:    instance.Spec.Source.GitURL,
+			Branch: instance.Spec.Source.GitBranch,
+		}
+		if devfileSpec.Git != nil {
+			gitSpec = *devfileSpec.Git
+		}
+		if gitSpec.Branch == "" {
+			gitSpec.Branch = "master"
+		}
+		if gitSpec.Path == "" {
+			gitSpec.Path = "devfile.yaml"
+		}
+
+		if gitSpec.Type == "" {
+			gitType, err := r.getGitServerType(instance, gitSpec.URL)
+			if err != nil {
+				return "", err
+			}
+			gitSpec.Type = gitType
+		}
+
+		rawURL, err := util.GetRawFileURL(gitSpec.URL, gitSpec.Type, gitSpec.Branch, gitSpec.Path)
 		if err != nil {
-			logrus.Errorf("Error to converting %s to JSON", devfileRawURL)
-			return "", reconcile.Result{}, err
+			return "", err
 		}
-		devfile = string(bodyJSON)
-	} else {
-		logrus.Errorf("Error (%v) when getting Devfile from %s", httpResponse.StatusCode, devfileRawURL)
-		return "", reconcile.Result{}, err
+		devfileURL = rawURL
+	}
+
+	devfile := []byte{}
+	// Raw files can be served from another host, e.g. by Bitbucket and GitHub Enterprise
+	if _, err := httpclient.New(devfileURL, transport).FollowRedirects().Do(context.TODO(), &httpclient.Request{Method: http.MethodGet}, &devfile); err != nil {
+		logrus.Errorf("Error when getting Devfile from %s: %v", devfileURL, err)
+		return "", err
 	}
 
-	return devfile, reconcile.Result{}, nil
+	return string(devfile), nil
 }
 
-func getUserToken(instance *openshiftv1alpha1.Workshop, username string,
-	cheNamespace string, appsHostnameSuffix string) (string, reconcile.Result, error) {
-	var (
-		openshiftUserPassword = instance.Spec.User.Password
-		err                   error
-		httpResponse          *http.Response
-		httpRequest           *http.Request
-		keycloakCheTokenURL   = "http://keycloak-" + cheNamespace + "." + appsHostnameSuffix + "/auth/realms/che/protocol/openid-connect/token"
-		oauthOpenShiftURL     = "https://oauth-openshift." + appsHostnameSuffix + "/oauth/authorize?client_id=openshift-challenging-client&response_type=token"
-
-		userToken util.Token
-		client    = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
+// renderDevfile renders the devfile template for a user and converts it to JSON
+func renderDevfile(devfileTemplate string, data cheDevfileData) (string, error) {
+	tmpl, err := template.New("devfile").Option("missingkey=error").Parse(devfileTemplate)
+	if err != nil {
+		logrus.Errorf("Error when parsing the devfile: %v", err)
+		return "", err
+	}
+
+	var devfile bytes.Buffer
+	if err := tmpl.Execute(&devfile, data); err != nil {
+		logrus.Errorf("Error when rendering the devfile for %s: %v", data.Username, err)
+		return "", err
+	}
+
+	devfileJSON, err := yaml.YAMLToJSON(devfile.Bytes())
+	if err != nil {
+		logrus.Errorf("Error to converting the devfile of %s to JSON: %v", data.Username, err)
+		return "", err
+	}
+
+	return string(devfileJSON), nil
+}
+
+// injectDevfileEnv adds the variables to the dockerimage components of the devfile in JSON,
+// keeping the values already set by the devfile
+func injectDevfileEnv(devfile string, env []corev1.EnvVar) (string, error) {
+	if len(env) == 0 {
+		return devfile, nil
+	}
+
+	devfileContent := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(devfile), &devfileContent); err != nil {
+		logrus.Errorf("Error when reading the devfile: %v", err)
+		return "", err
+	}
+
+	components, _ := devfileContent["components"].([]interface{})
+	for _, c := range components {
+		component, ok := c.(map[string]interface{})
+		if !ok || component["type"] != "dockerimage" {
+			continue
 		}
-	)
 
-	// GET TOKEN
-	httpRequest, err = http.NewRequest("GET", oauthOpenShiftURL, nil)
-	httpRequest.Header.Set("Authorization", "Basic "+util.GetBasicAuth(username, openshiftUserPassword))
-	httpRequest.Header.Set("X-CSRF-Token", "xxx")
+		componentEnv, _ := component["env"].([]interface{})
+		names := map[string]bool{}
+		for _, e := range componentEnv {
+			if variable, ok := e.(map[string]interface{}); ok {
+				if name, ok := variable["name"].(string); ok {
+					names[name] = true
+				}
+			}
+		}
+		for _, variable := range env {
+			if !names[variable.Name] {
+				componentEnv = append(componentEnv, map[string]interface{}{"name": variable.Name, "value": variable.Value})
+			}
+		}
+		component["env"] = componentEnv
+	}
+
+	devfileJSON, err := json.Marshal(devfileContent)
+	if err != nil {
+		return "", err
+	}
+
+	return string(devfileJSON), nil
+}
+
+// getComponentURL returns the URL of the route of a component, falling back to the URL reported
+// by its operator when the route can not be found
+func (r *ReconcileWorkshop) getComponentURL(routeName string, namespace string, reportedURL string) (string, error) {
+	routeURL, err := r.getRouteURL(routeName, namespace)
+	if err != nil {
+		return "", err
+	}
+	if routeURL == "" {
+		return reportedURL, nil
+	}
+	return routeURL, nil
+}
+
+// getUserToken exchanges an OpenShift access token of the user for a Che token
+func getUserToken(ctx context.Context, oauthClient *oauth.Client, keycloakClient *keycloak.Client,
+	username string, password string) (string, error) {
 
-	httpResponse, err = client.Do(httpRequest)
+	openshiftToken, err := oauthClient.GetAccessToken(ctx, username, password)
 	if err != nil {
 		logrus.Errorf("Error when getting Token Exchange for %s: %v", username, err)
-		return "", reconcile.Result{}, err
+		return "", err
+	}
+
+	userToken, err := keycloakClient.ExchangeToken(ctx, "che", "che-public", openshiftToken, "openshift-v4")
+	if err != nil {
+		logrus.Errorf("Error to get the user access token from che keycloak for %s: %v", username, err)
+		return "", err
 	}
 
-	if httpResponse.StatusCode == http.StatusFound {
-		locationURL, err := url.Parse(httpResponse.Header.Get("Location"))
+	return userToken.AccessToken, nil
+}
+
+// getKeycloakUserToken provisions the user in the Che realm with the Keycloak admin API and returns
+// its Che token. It does not need the password of the user, so it works with any OpenShift identity
+// provider. The user is linked to its OpenShift account once the OpenShift User exists, after the
+// first login, and linked is false until then.
+func (r *ReconcileWorkshop) getKeycloakUserToken(ctx context.Context, instance *openshiftv1alpha1.Workshop, keycloakClient *keycloak.Client,
+	adminToken string, username string, passwords *cheUserPasswords) (token string, linked bool, err error) {
+
+	user, err := keycloakClient.GetUser(ctx, adminToken, "che", username)
+	if httpclient.IsNotFound(err) {
+		user, err = keycloakClient.CreateUser(ctx, adminToken, "che", &keycloak.User{
+			Username:      username,
+			Email:         username + "@none.com",
+			EmailVerified: true,
+			Enabled:       true,
+			Attributes:    map[string][]string{provisionedByAttribute: {getWorkshopID(instance)}},
+		})
 		if err != nil {
-			return "", reconcile.Result{}, err
+			logrus.Errorf("Error when provisioning %s user in che keycloak: %v", username, err)
+			return "", false, err
 		}
+		logrus.Infof("Created %s user in che keycloak", username)
+	} else if err != nil {
+		logrus.Errorf("Error when getting %s user from che keycloak: %v", username, err)
+		return "", false, err
+	}
 
-		regex := regexp.MustCompile("access_token=([^&]+)")
-		subjectToken := regex.FindStringSubmatch(locationURL.Fragment)
+	// Keycloak identifies OpenShift accounts by the uid of their User, which only exists after the first login
+	openshiftUser := &openshiftuser.User{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: username}, openshiftUser); err == nil {
+		linked = true
+		if err := linkOpenShiftIdentity(ctx, keycloakClient, adminToken, user, keycloak.FederatedIdentity{
+			IdentityProvider: "openshift-v4",
+			UserID:           string(openshiftUser.UID),
+			UserName:         username,
+		}); err != nil {
+			return "", false, err
+		}
+	} else if !errors.IsNotFound(err) {
+		return "", false, err
+	}
 
-		// Get User Access Token
-		data := url.Values{}
-		data.Set("client_id", "che-public")
-		data.Set("grant_type", "urn:ietf:params:oauth:grant-type:token-exchange")
-		data.Set("subject_token", subjectToken[1])
-		data.Set("subject_issuer", "openshift-v4")
-		data.Set("subject_token_type", "urn:ietf:params:oauth:token-type:access_token")
+	token, err = getKeycloakPasswordToken(ctx, keycloakClient, adminToken, user, passwords)
+	return token, linked, err
+}
 
-		httpRequest, err = http.NewRequest("POST", keycloakCheTokenURL, strings.NewReader(data.Encode()))
-		httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		httpResponse, err = client.Do(httpRequest)
-		if err != nil {
-			logrus.Errorf("Error to get the user access  token from che keycloak (%v)", err)
-			return "", reconcile.Result{}, err
-		}
-		defer httpResponse.Body.Close()
-		if httpResponse.StatusCode == http.StatusOK {
-			if err := json.NewDecoder(httpResponse.Body).Decode(&userToken); err != nil {
-				logrus.Errorf("Error to get the user access  token from che keycloak (%v)", err)
-				return "", reconcile.Result{}, err
-			}
-		} else {
-			logrus.Errorf("Error to get the user access token from che keycloak (%d)", httpResponse.StatusCode)
-			return "", reconcile.Result{}, err
+// linkOpenShiftIdentity links an existing Keycloak user, created by the operator or by the first login, to its OpenShift account
+func linkOpenShiftIdentity(ctx context.Context, keycloakClient *keycloak.Client, adminToken string,
+	user *keycloak.User, openshiftIdentity keycloak.FederatedIdentity) error {
+
+	identities, err := keycloakClient.GetFederatedIdentities(ctx, adminToken, "che", user.ID)
+	if err != nil {
+		logrus.Errorf("Error when getting the identities of %s: %v", user.Username, err)
+		return err
+	}
+	for _, identity := range identities {
+		if identity.IdentityProvider == openshiftIdentity.IdentityProvider {
+			return nil
 		}
-	} else {
-		logrus.Errorf("Error when getting Token Exchange for %s (%d)", username, httpResponse.StatusCode)
-		return "", reconcile.Result{}, err
 	}
 
-	return userToken.AccessToken, reconcile.Result{}, nil
+	if err := keycloakClient.AddFederatedIdentity(ctx, adminToken, "che", user.ID, &openshiftIdentity); err != nil {
+		logrus.Errorf("Error when linking %s to its OpenShift account: %v", user.Username, err)
+		return err
+	}
+	logrus.Infof("Linked %s user in che keycloak to its OpenShift account", user.Username)
+
+	return nil
 }
 
-func updateUserEmail(instance *openshiftv1alpha1.Workshop, username string,
-	cheNamespace string, appsHostnameSuffix string) (reconcile.Result, error) {
-	var (
-		err                    error
-		httpResponse           *http.Response
-		httpRequest            *http.Request
-		keycloakMasterTokenURL = "http://keycloak-" + cheNamespace + "." + appsHostnameSuffix + "/auth/realms/master/protocol/openid-connect/token"
-		keycloakUserURL        = "http://keycloak-" + cheNamespace + "." + appsHostnameSuffix + "/auth/admin/realms/che/users"
-		masterToken            util.Token
-		client                 = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
-		cheUser []struct {
-			ID    string `json:"id"`
-			Email string `json:"email"`
+// getKeycloakPasswordToken gets the Che token of the user with the password stored by the operator,
+// and only sets a new random password when there is none or it no longer works.
+// The Keycloak password is only used by the operator, users log in through OpenShift.
+func getKeycloakPasswordToken(ctx context.Context, keycloakClient *keycloak.Client,
+	adminToken string, user *keycloak.User, passwords *cheUserPasswords) (string, error) {
+
+	if password := passwords.get(user.Username); password != "" {
+		userToken, err := keycloakClient.GetUserToken(ctx, "che", "che-public", user.Username, password)
+		if err == nil {
+			return userToken.AccessToken, nil
 		}
-	)
+		if !httpclient.IsStatus(err, http.StatusUnauthorized) && !httpclient.IsStatus(err, http.StatusBadRequest) {
+			logrus.Errorf("Error to get the user access token from che keycloak for %s: %v", user.Username, err)
+			return "", err
+		}
+		logrus.Infof("Stored password of %s was rejected by che keycloak, setting a new one", user.Username)
+	}
 
-	// Get Keycloak Admin Token
-	httpRequest, err = http.NewRequest("POST", keycloakMasterTokenURL, strings.NewReader("username=admin&password=admin&grant_type=password&client_id=admin-cli"))
-	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	httpResponse, err = client.Do(httpRequest)
+	password, err := util.GeneratePassword(24)
 	if err != nil {
-		logrus.Errorf("Error when getting the master token from che keycloak (%v)", err)
-		return reconcile.Result{}, err
+		return "", err
 	}
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == http.StatusOK {
-		if err := json.NewDecoder(httpResponse.Body).Decode(&masterToken); err != nil {
-			logrus.Errorf("Error when reading the master token: %v", err)
-			return reconcile.Result{}, err
+	if err := keycloakClient.ResetPassword(ctx, adminToken, "che", user.ID, password); err != nil {
+		logrus.Errorf("Error when setting the password of %s in che keycloak: %v", user.Username, err)
+		return "", err
+	}
+	passwords.set(user.Username, password)
+
+	userToken, err := keycloakClient.GetUserToken(ctx, "che", "che-public", user.Username, password)
+	if err != nil {
+		logrus.Errorf("Error to get the user access token from che keycloak for %s: %v", user.Username, err)
+		return "", err
+	}
+
+	return userToken.AccessToken, nil
+}
+
+// cheUserPasswords holds the Keycloak passwords set by the operator to get the Che token of the users,
+// stored in a Secret of the Workshop namespace so that they are not reset on every reconciliation
+type cheUserPasswords struct {
+	secret  *corev1.Secret
+	changed bool
+}
+
+func (p *cheUserPasswords) get(username string) string {
+	return string(p.secret.Data[username])
+}
+
+func (p *cheUserPasswords) set(username string, password string) {
+	p.secret.Data[username] = []byte(password)
+	p.changed = true
+}
+
+func (p *cheUserPasswords) delete(username string) {
+	if _, ok := p.secret.Data[username]; ok {
+		delete(p.secret.Data, username)
+		p.changed = true
+	}
+}
+
+func (r *ReconcileWorkshop) getCheUserPasswords(instance *openshiftv1alpha1.Workshop) (*cheUserPasswords, error) {
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "che-user-passwords", Namespace: instance.Namespace}, secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
 		}
-	} else {
-		logrus.Errorf("Error when getting the master token from che keycloak (%d)", httpResponse.StatusCode)
-		return reconcile.Result{}, err
+		secret = deployment.NewSecretStringData(instance, "che-user-passwords", instance.Namespace, nil)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	return &cheUserPasswords{secret: secret}, nil
+}
+
+func (r *ReconcileWorkshop) saveCheUserPasswords(passwords *cheUserPasswords) error {
+	if !passwords.changed {
+		return nil
+	}
+
+	if passwords.secret.ResourceVersion == "" {
+		if err := r.client.Create(context.TODO(), passwords.secret); err != nil {
+			return err
+		}
+		logrus.Infof("Created %s Secret", passwords.secret.Name)
+	} else if err := r.client.Update(context.TODO(), passwords.secret); err != nil {
+		return err
+	}
+
+	passwords.changed = false
+	return nil
+}
+
+// provisionedByAttribute marks the Keycloak users provisioned by a Workshop
+const provisionedByAttribute = "openshift-workshop"
+
+// getWorkshopID identifies the Workshop in the provisionedByAttribute of its users
+func getWorkshopID(instance *openshiftv1alpha1.Workshop) string {
+	return instance.Namespace + "/" + instance.Name
+}
+
+func isProvisionedBy(user *keycloak.User, workshopID string) bool {
+	for _, value := range user.Attributes[provisionedByAttribute] {
+		if value == workshopID {
+			return true
+		}
+	}
+	return false
+}
+
+// deprovisionCheUsers deletes the workspaces and the Keycloak accounts of the workshop users
+// numbered above users and provisioned by the Workshop, or only lists them in dry run. Running workspaces are stopped first
+// and deleted once stopped, so the removal may take a few reconciliations.
+func (r *ReconcileWorkshop) deprovisionCheUsers(ctx context.Context, instance *openshiftv1alpha1.Workshop,
+	keycloakClient *keycloak.Client, cheClient *checlient.Client, adminToken string, users int,
+	passwords *cheUserPasswords) (reconcile.Result, error) {
+
+	// Without users, the Workshop is most likely misconfigured
+	if users == 0 {
+		return reconcile.Result{}, nil
 	}
 
-	// GET USER
-	httpRequest, err = http.NewRequest("GET", keycloakUserURL+"?username="+username, nil)
-	httpRequest.Header.Set("Authorization", "Bearer "+masterToken.AccessToken)
+	dryRun := instance.Spec.Infrastructure.Che.DeprovisionDryRun
+	workshopID := getWorkshopID(instance)
+	userRegexp := regexp.MustCompile("^user([0-9]+)$")
 
-	httpResponse, err = client.Do(httpRequest)
+	keycloakUsers, err := keycloakClient.ListUsers(ctx, adminToken, "che")
 	if err != nil {
-		logrus.Errorf("Error when getting %s user: %v", username, err)
+		logrus.Errorf("Error when listing the users of che keycloak: %v", err)
 		return reconcile.Result{}, err
 	}
 
-	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == http.StatusOK {
-		if err := json.NewDecoder(httpResponse.Body).Decode(&cheUser); err != nil {
-			logrus.Errorf("Error to get the user info (%v)", err)
+	deprovisionStatus := []openshiftv1alpha1.CheDeprovisionStatus{}
+	pending := false
+	for i := range keycloakUsers {
+		user := &keycloakUsers[i]
+		match := userRegexp.FindStringSubmatch(user.Username)
+		if match == nil {
+			continue
+		}
+		if id, _ := strconv.Atoi(match[1]); id >= 1 && id <= users {
+			continue
+		}
+		if !isProvisionedBy(user, workshopID) {
+			continue
+		}
+
+		userAccessToken, err := getKeycloakPasswordToken(ctx, keycloakClient, adminToken, user, passwords)
+		if err != nil {
 			return reconcile.Result{}, err
 		}
 
-		if cheUser[0].Email == "" {
-			httpRequest, err = http.NewRequest("PUT", keycloakUserURL+"/"+cheUser[0].ID,
-				strings.NewReader(`{"email":"`+username+`@none.com"}`))
-			httpRequest.Header.Set("Content-Type", "application/json")
-			httpRequest.Header.Set("Authorization", "Bearer "+masterToken.AccessToken)
-			httpResponse, err = client.Do(httpRequest)
-			if err != nil {
-				logrus.Errorf("Error when update email address for %s: %v", username, err)
-				return reconcile.Result{}, err
+		workspaces, err := cheClient.ListWorkspaces(ctx, userAccessToken)
+		if err != nil {
+			logrus.Errorf("Error when listing the workspaces of %s: %v", user.Username, err)
+			return reconcile.Result{}, err
+		}
+
+		userStatus := openshiftv1alpha1.CheDeprovisionStatus{User: user.Username}
+		for _, workspace := range workspaces {
+			userStatus.Workspaces = append(userStatus.Workspaces, workspace.ID)
+		}
+		deprovisionStatus = append(deprovisionStatus, userStatus)
+
+		if dryRun {
+			logrus.Infof("Dry run: would delete %s user and its %d workspaces", user.Username, len(workspaces))
+			continue
+		}
+
+		remaining := 0
+		for _, workspace := range workspaces {
+			switch workspace.Status {
+			case checlient.WorkspaceStopped:
+				if err := cheClient.DeleteWorkspace(ctx, userAccessToken, workspace.ID); err != nil {
+					logrus.Errorf("Error when deleting the workspace %s of %s: %v", workspace.ID, user.Username, err)
+					return reconcile.Result{}, err
+				}
+				logrus.Infof("Deleted Workspace %s of %s", workspace.ID, user.Username)
+			case checlient.WorkspaceRunning, checlient.WorkspaceStarting:
+				if err := cheClient.StopWorkspace(ctx, userAccessToken, workspace.ID); err != nil {
+					logrus.Errorf("Error when stopping the workspace %s of %s: %v", workspace.ID, user.Username, err)
+					return reconcile.Result{}, err
+				}
+				remaining++
+			default:
+				remaining++
 			}
 		}
-	} else {
-		logrus.Errorf("Error when getting %s user: %v", username, httpResponse.StatusCode)
+
+		if remaining > 0 {
+			pending = true
+			continue
+		}
+
+		if err := keycloakClient.DeleteUser(ctx, adminToken, "che", user.ID); err != nil {
+			logrus.Errorf("Error when deleting %s user from che keycloak: %v", user.Username, err)
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Deleted %s user from che keycloak", user.Username)
+		passwords.delete(user.Username)
+	}
+
+	if err := r.saveCheUserPasswords(passwords); err != nil {
 		return reconcile.Result{}, err
 	}
 
-	//Success
+	if len(deprovisionStatus) == 0 {
+		deprovisionStatus = nil
+	}
+	if !reflect.DeepEqual(instance.Status.CheDeprovision, deprovisionStatus) {
+		instance.Status.CheDeprovision = deprovisionStatus
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if pending {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
 	return reconcile.Result{}, nil
 }
 
-func initWorkspace(instance *openshiftv1alpha1.Workshop, username string,
-	userAccessToken string, devfile string,
-	appsHostnameSuffix string) (reconcile.Result, error) {
+// updateProvisionedUser sets a placeholder email address, required by Che, to users without one
+// and marks the users provisioned for the Workshop, the only ones that can be deprovisioned
+func updateProvisionedUser(ctx context.Context, instance *openshiftv1alpha1.Workshop, keycloakClient *keycloak.Client,
+	adminToken string, username string) error {
+	user, err := keycloakClient.GetUser(ctx, adminToken, "che", username)
+	if err != nil {
+		logrus.Errorf("Error when getting %s user: %v", username, err)
+		return err
+	}
 
-	var (
-		err                 error
-		httpResponse        *http.Response
-		httpRequest         *http.Request
-		devfileWorkspaceURL = "http://che-eclipse-che." + appsHostnameSuffix + "/api/workspace/devfile?start-after-create=true&namespace=" + username
-
-		client = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
+	workshopID := getWorkshopID(instance)
+	if user.Email != "" && isProvisionedBy(user, workshopID) {
+		return nil
+	}
+
+	update := &keycloak.User{ID: user.ID, Email: user.Email}
+	if update.Email == "" {
+		update.Email = username + "@none.com"
+	}
+	if !isProvisionedBy(user, workshopID) {
+		update.Attributes = map[string][]string{}
+		for key, values := range user.Attributes {
+			update.Attributes[key] = values
 		}
-	)
+		update.Attributes[provisionedByAttribute] = append(update.Attributes[provisionedByAttribute], workshopID)
+	}
+	if err := keycloakClient.UpdateUser(ctx, adminToken, "che", update); err != nil {
+		logrus.Errorf("Error when updating %s user: %v", username, err)
+		return err
+	}
 
-	httpRequest, err = http.NewRequest("POST", devfileWorkspaceURL, strings.NewReader(devfile))
-	httpRequest.Header.Set("Authorization", "Bearer "+userAccessToken)
-	httpRequest.Header.Set("Content-Type", "application/json")
-	httpRequest.Header.Set("Accept", "application/json")
+	return nil
+}
 
-	httpResponse, err = client.Do(httpRequest)
+// reconcileWorkspace creates the workspace of the user from the devfile when it does not exist yet
+// and brings it to the requested state
+func reconcileWorkspace(ctx context.Context, instance *openshiftv1alpha1.Workshop, cheClient *checlient.Client,
+	username string, userAccessToken string, devfile string) (*checlient.Workspace, error) {
+
+	workspaceState := instance.Spec.Infrastructure.Che.Workspace.State
+
+	workspace, err := getWorkspace(ctx, cheClient, username, userAccessToken, devfile)
 	if err != nil {
-		logrus.Errorf("Error when creating the workspace for %s: %v", username, err)
-		return reconcile.Result{}, err
+		return nil, err
 	}
-	defer httpResponse.Body.Close()
-	// if httpResponse.StatusCode == http.StatusOK {
-	// 	logrus.Infof("Started Workspace for %s", username)
-	// } else {
-	// 	logrus.Errorf("Error (%d) when creating the workspace for %s", httpResponse.StatusCode, username)
-	// 	return reconcile.Result{}, err
-	// }
 
-	//Success
-	return reconcile.Result{}, nil
+	if workspace == nil {
+		workspace, err = cheClient.CreateWorkspace(ctx, userAccessToken, username, []byte(devfile), workspaceState != "Stopped")
+		if err != nil {
+			logrus.Errorf("Error when creating the workspace for %s: %v", username, err)
+			return nil, err
+		}
+		logrus.Infof("Created Workspace %s for %s", workspace.ID, username)
+		return workspace, nil
+	}
+
+	if workspaceState == "Running" && workspace.Status == checlient.WorkspaceStopped {
+		workspace, err = cheClient.StartWorkspace(ctx, userAccessToken, workspace.ID)
+		if err != nil {
+			logrus.Errorf("Error when starting the workspace for %s: %v", username, err)
+			return nil, err
+		}
+		logrus.Infof("Started Workspace %s for %s", workspace.ID, username)
+	} else if workspaceState == "Stopped" &&
+		(workspace.Status == checlient.WorkspaceRunning || workspace.Status == checlient.WorkspaceStarting) {
+		if err := cheClient.StopWorkspace(ctx, userAccessToken, workspace.ID); err != nil {
+			logrus.Errorf("Error when stopping the workspace for %s: %v", username, err)
+			return nil, err
+		}
+		workspace.Status = checlient.WorkspaceStopping
+		logrus.Infof("Stopped Workspace %s for %s", workspace.ID, username)
+	}
+
+	return workspace, nil
+}
+
+// getWorkspace returns the workspace of the user created from the devfile or nil if there is none
+func getWorkspace(ctx context.Context, cheClient *checlient.Client,
+	username string, userAccessToken string, devfile string) (*checlient.Workspace, error) {
+
+	devfileContent := checlient.Devfile{}
+	if err := json.Unmarshal([]byte(devfile), &devfileContent); err != nil {
+		logrus.Errorf("Error when reading the devfile: %v", err)
+		return nil, err
+	}
+
+	workspaces, err := cheClient.ListWorkspaces(ctx, userAccessToken)
+	if err != nil {
+		logrus.Errorf("Error when listing the workspaces of %s: %v", username, err)
+		return nil, err
+	}
+
+	for i := range workspaces {
+		// Devfiles without name get a generated one, so any workspace of the user matches
+		if devfileContent.Metadata.Name == "" || workspaces[i].Devfile.Metadata.Name == devfileContent.Metadata.Name {
+			return &workspaces[i], nil
+		}
+	}
+
+	return nil, nil
 }
@@ -3,102 +3,354 @@ package workshop
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"reflect"
 	"strings"
+	"time"
 
 	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/etherpad"
 	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
 	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// etherpadPadName is the pad created by the operator to list the users and their guides
+const etherpadPadName = "welcome"
+
 // Reconciling Etherpad
-func (r *ReconcileWorkshop) reconcileEtherpad(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) error {
+func (r *ReconcileWorkshop) reconcileEtherpad(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) (reconcile.Result, error) {
 	enabledEtherpad := instance.Spec.Infrastructure.Etherpad.Enabled
 
 	if enabledEtherpad {
-		if err := r.addEtherpad(instance, users, appsHostnameSuffix); err != nil {
-			return err
-		}
+		return r.addEtherpad(instance, users, appsHostnameSuffix)
 	}
 
 	//Success
-	return nil
+	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileWorkshop) addEtherpad(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) error {
+func (r *ReconcileWorkshop) addEtherpad(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) (reconcile.Result, error) {
 	reqLogger := log.WithName("Etherpad")
 
-	var userEndpointStr strings.Builder
-	for id := 1; id <= users; id++ {
-		userEndpointStr.WriteString(fmt.Sprintf("You are user%d\t|\thttp://guide-infra%d.%s\t|\t<INSERT_YOUR_NAME>\n", id, id, appsHostnameSuffix))
+	// Credentials are generated once, an existing Secret is never updated
+	databasePassword, err := util.GeneratePassword(16)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	databaseRootPassword, err := util.GeneratePassword(16)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
-
 	databaseCredentials := map[string]string{
 		"database-name":          "sampledb",
-		"database-password":      "admin",
-		"database-root-password": "admin",
-		"database-user":          "admin",
+		"database-password":      databasePassword,
+		"database-root-password": databaseRootPassword,
+		"database-user":          "etherpad",
 	}
 	etherpadDatabaseSecret := deployment.NewSecretStringData(instance, "etherpad-mysql", instance.Namespace, databaseCredentials)
 	if err := r.client.Create(context.TODO(), etherpadDatabaseSecret); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created %s Secret", etherpadDatabaseSecret.Name)
 	}
 
+	adminPassword, err := util.GeneratePassword(16)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	etherpadAdminSecret := deployment.NewSecretStringData(instance, "etherpad-admin", instance.Namespace, map[string]string{
+		"username": "admin",
+		"password": adminPassword,
+	})
+	if err := r.client.Create(context.TODO(), etherpadAdminSecret); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Secret", etherpadAdminSecret.Name)
+	}
+
+	apiKey, err := util.GeneratePassword(32)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	etherpadAPISecret := deployment.NewSecretStringData(instance, "etherpad-api", instance.Namespace, map[string]string{
+		"APIKEY.txt": apiKey,
+	})
+	if err := r.client.Create(context.TODO(), etherpadAPISecret); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Secret", etherpadAPISecret.Name)
+	}
+
 	etherpadDatabasePersistentVolumeClaim := deployment.NewPersistentVolumeClaim(instance, "etherpad-mysql", instance.Namespace, "512Mi")
 	if err := r.client.Create(context.TODO(), etherpadDatabasePersistentVolumeClaim); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created %s Persistent Volume Claim", etherpadDatabasePersistentVolumeClaim.Name)
 	}
 
 	etherpadDatabaseDeployment := deployment.NewEtherpadDatabaseDeployment(instance, "etherpad-mysql", instance.Namespace)
 	if err := r.client.Create(context.TODO(), etherpadDatabaseDeployment); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created %s Database", etherpadDatabaseDeployment.Name)
 	}
 
 	etherpadDatabaseService := deployment.NewService(instance, "etherpad-mysql", instance.Namespace, []string{"mysql"}, []int32{3306})
 	if err := r.client.Create(context.TODO(), etherpadDatabaseService); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created Etherpad SQL Service")
 		logrus.Infof("Created %s Service", etherpadDatabaseService.Name)
 	}
 
-	settings := map[string]string{
-		"settings.json": deployment.NewEtherpadSettingsJson(instance, userEndpointStr.String()),
-	}
-	etherpadConfigMap := deployment.NewConfigMap(instance, "etherpad-settings", instance.Namespace, settings)
-	if err := r.client.Create(context.TODO(), etherpadConfigMap); err != nil && !errors.IsAlreadyExists(err) {
-		return err
-	} else if err == nil {
-		logrus.Infof("Created %s ConfigMap", etherpadConfigMap.Name)
+	// Invalid settings keep the ConfigMap as it is and are reported in the EtherpadSettingsValid condition
+	settingsJSON, settingsErr := deployment.NewEtherpadSettingsJson(instance)
+	if settingsErr != nil {
+		logrus.Errorf("Error when generating the Etherpad settings: %v", settingsErr)
+	} else {
+		settings := map[string]string{
+			"settings.json": settingsJSON,
+		}
+		etherpadConfigMap := deployment.NewConfigMap(instance, "etherpad-settings", instance.Namespace, settings)
+		if err := r.client.Create(context.TODO(), etherpadConfigMap); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			logrus.Infof("Created %s ConfigMap", etherpadConfigMap.Name)
+		} else if foundConfigMap := r.GetEffectiveConfigMap(instance, etherpadConfigMap.Name, instance.Namespace); foundConfigMap != nil &&
+			!reflect.DeepEqual(foundConfigMap.Data, settings) {
+			foundConfigMap.Data = settings
+			// Etherpad reads its settings on start
+			if foundConfigMap.Annotations == nil {
+				foundConfigMap.Annotations = map[string]string{}
+			}
+			foundConfigMap.Annotations[rolloutPendingAnnotation] = "true"
+			if err := r.client.Update(context.TODO(), foundConfigMap); err != nil {
+				return reconcile.Result{}, err
+			}
+			logrus.Infof("Updated %s ConfigMap", foundConfigMap.Name)
+		}
 	}
 
 	etherpadDeployment := deployment.NewEtherpadDeployment(instance, "etherpad", instance.Namespace)
 	if err := r.client.Create(context.TODO(), etherpadDeployment); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created %s Deployment", etherpadDeployment.Name)
 	}
 
+	// Deployments created by earlier versions of the operator lack the environment and the volume of the admin and API Secrets
+	etherpadDeploymentFound := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: etherpadDeployment.Name, Namespace: instance.Namespace}, etherpadDeploymentFound); err != nil {
+		return reconcile.Result{}, err
+	}
+	if !reflect.DeepEqual(etherpadDeploymentFound.Spec.Template.Spec.Containers[0].Env, etherpadDeployment.Spec.Template.Spec.Containers[0].Env) ||
+		!reflect.DeepEqual(etherpadDeploymentFound.Spec.Template.Spec.Containers[0].VolumeMounts, etherpadDeployment.Spec.Template.Spec.Containers[0].VolumeMounts) {
+		etherpadDeploymentFound.Spec.Template.Spec.Containers[0].Env = etherpadDeployment.Spec.Template.Spec.Containers[0].Env
+		etherpadDeploymentFound.Spec.Template.Spec.Containers[0].VolumeMounts = etherpadDeployment.Spec.Template.Spec.Containers[0].VolumeMounts
+		etherpadDeploymentFound.Spec.Template.Spec.Volumes = etherpadDeployment.Spec.Template.Spec.Volumes
+		if err := r.client.Update(context.TODO(), etherpadDeploymentFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Updated %s Deployment", etherpadDeploymentFound.Name)
+	}
+
+	if err := r.rolloutEtherpad(instance, "etherpad-settings", etherpadDeployment.Name); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The Secret is only published once the settings read the admin password from it and Etherpad restarted with them.
+	// ConfigMaps of earlier versions of the operator used the database root password, the settings overlay may set another one.
+	etherpadAdminSecretName := ""
+	if strings.Contains(settingsJSON, "${ETHERPAD_ADMIN_PASSWORD}") {
+		etherpadAdminSecretName = etherpadAdminSecret.Name
+	}
+	if settingsErr == nil && instance.Status.EtherpadAdminSecret != etherpadAdminSecretName {
+		instance.Status.EtherpadAdminSecret = etherpadAdminSecretName
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	etherpadService := deployment.NewService(instance, "etherpad", instance.Namespace, []string{"http"}, []int32{9001})
 	if err := r.client.Create(context.TODO(), etherpadService); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created %s Service", etherpadService.Name)
 	}
 
 	etherpadRoute := deployment.NewRoute(instance, "etherpad", instance.Namespace, "etherpad", 9001)
 	if err := r.client.Create(context.TODO(), etherpadRoute); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		logrus.Infof("Created %s Route", etherpadRoute.Name)
 	}
 
+	padData, err := r.getEtherpadPadData(instance, users, appsHostnameSuffix)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	padText, padErr := deployment.RenderEtherpadPad(instance.Spec.Infrastructure.Etherpad.PadTemplate, padData)
+	if padErr != nil {
+		logrus.Errorf("Error when rendering the Etherpad pad: %v", padErr)
+	}
+
+	// Invalid settings or pad template wait for the spec to be fixed
+	invalid := []string{}
+	for _, err := range []error{settingsErr, padErr} {
+		if err != nil {
+			invalid = append(invalid, err.Error())
+		}
+	}
+	if len(invalid) > 0 {
+		if err := r.updateCondition(instance, openshiftv1alpha1.EtherpadSettingsValid, corev1.ConditionFalse, "InvalidSettings", strings.Join(invalid, "; ")); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else if err := r.updateCondition(instance, openshiftv1alpha1.EtherpadSettingsValid, corev1.ConditionTrue, "", ""); err != nil {
+		return reconcile.Result{}, err
+	}
+	if padErr != nil {
+		return reconcile.Result{}, nil
+	}
+
+	return r.updateEtherpadPad(instance, padText)
+}
+
+// updateEtherpadPad writes the text to the welcome pad through the Etherpad API when it changed
+// since the last update, so that the edits of the attendees are kept otherwise
+func (r *ReconcileWorkshop) updateEtherpadPad(instance *openshiftv1alpha1.Workshop, padText string) (reconcile.Result, error) {
+	ctx := context.TODO()
+
+	etherpadURL, admitted, err := r.getAdmittedRouteURL("etherpad", instance.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	} else if !admitted {
+		logrus.Infof("Waiting for the Etherpad route to update the %s pad", etherpadPadName)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	checksum, err := getChecksum(padText)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	status := &openshiftv1alpha1.EtherpadPadStatus{
+		Name:     etherpadPadName,
+		URL:      etherpadURL + "/p/" + url.PathEscape(etherpadPadName),
+		Checksum: checksum,
+	}
+	if reflect.DeepEqual(instance.Status.EtherpadPad, status) {
+		return reconcile.Result{}, nil
+	}
+
+	apiKey, err := r.getSecretKey("etherpad-api", instance.Namespace, "APIKEY.txt")
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	transport, err := r.newHTTPTransport(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	etherpadClient := etherpad.New(etherpadURL, transport, string(apiKey))
+	if err := etherpadClient.Ping(ctx); err != nil {
+		logrus.Infof("Waiting for Etherpad to be available: %v", err)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	padIDs, err := etherpadClient.ListAllPads(ctx)
+	if err != nil {
+		logrus.Errorf("Error when listing the Etherpad pads: %v", err)
+		return reconcile.Result{}, err
+	}
+
+	padFound := false
+	for _, padID := range padIDs {
+		if padID == etherpadPadName {
+			padFound = true
+		}
+	}
+
+	if !padFound {
+		if err := etherpadClient.CreatePad(ctx, etherpadPadName, padText); err != nil {
+			logrus.Errorf("Error when creating the %s pad: %v", etherpadPadName, err)
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Created the %s pad", etherpadPadName)
+	} else {
+		if err := etherpadClient.SetText(ctx, etherpadPadName, padText); err != nil {
+			logrus.Errorf("Error when updating the %s pad: %v", etherpadPadName, err)
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Updated the %s pad", etherpadPadName)
+	}
+
+	instance.Status.EtherpadPad = status
+	if err := r.updateStatus(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	//Success
-	return nil
+	return reconcile.Result{}, nil
+}
+
+// rolloutEtherpad restarts Etherpad when its settings changed since it started
+func (r *ReconcileWorkshop) rolloutEtherpad(instance *openshiftv1alpha1.Workshop, configMapName string, deploymentName string) error {
+	settingsConfigMap := r.GetEffectiveConfigMap(instance, configMapName, instance.Namespace)
+	if settingsConfigMap == nil || settingsConfigMap.Annotations[rolloutPendingAnnotation] != "true" {
+		return nil
+	}
+
+	etherpadDeployment := &appsv1.Deployment{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: deploymentName, Namespace: instance.Namespace}, etherpadDeployment); err != nil {
+		return err
+	}
+	if etherpadDeployment.Spec.Template.Annotations == nil {
+		etherpadDeployment.Spec.Template.Annotations = map[string]string{}
+	}
+	etherpadDeployment.Spec.Template.Annotations["openshift.workshop/restartedAt"] = time.Now().Format(time.RFC3339)
+	if err := r.client.Update(context.TODO(), etherpadDeployment); err != nil {
+		return err
+	}
+	logrus.Infof("Rolled out %s Deployment to apply the Etherpad settings", etherpadDeployment.Name)
+
+	delete(settingsConfigMap.Annotations, rolloutPendingAnnotation)
+	return r.client.Update(context.TODO(), settingsConfigMap)
+}
+
+// getEtherpadPadData returns the users and the URLs of the workshop written to the welcome pad
+func (r *ReconcileWorkshop) getEtherpadPadData(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) (deployment.EtherpadPadData, error) {
+	gogsURL, err := r.getGogsURL(instance)
+	if err != nil {
+		return deployment.EtherpadPadData{}, err
+	}
+
+	data := deployment.EtherpadPadData{
+		WorkshopName:       instance.Name,
+		Users:              []deployment.EtherpadPadUser{},
+		GogsURL:            gogsURL,
+		NexusURL:           instance.Status.NexusURL,
+		CheURL:             instance.Status.CheURL,
+		AppsHostnameSuffix: appsHostnameSuffix,
+	}
+	for id := 1; id <= users; id++ {
+		guideURL := ""
+		if instance.Spec.Infrastructure.Workshopper.Enabled {
+			if guideURL, err = r.getRouteURL("guide", fmt.Sprintf("infra%d", id)); err != nil {
+				return deployment.EtherpadPadData{}, err
+			}
+		}
+		data.Users = append(data.Users, deployment.EtherpadPadUser{
+			Username:    fmt.Sprintf("user%d", id),
+			ProjectName: fmt.Sprintf("%s%d", instance.Spec.Infrastructure.Project.Name, id),
+			GuideURL:    guideURL,
+		})
+	}
+
+	return data, nil
 }
@@ -14,6 +14,7 @@ package workshop
 import (
 	"context"
 
+	checlusterv1 "github.com/eclipse/che-operator/pkg/apis/org/v1"
 	oauth "github.com/openshift/api/oauth/v1"
 	routev1 "github.com/openshift/api/route/v1"
 	olmv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
@@ -91,6 +92,36 @@ func (r *ReconcileWorkshop) GetEffectiveCSV(instance *openshiftv1alpha1.Workshop
 
 }
 
+func (r *ReconcileWorkshop) GetEffectiveSubscription(instance *openshiftv1alpha1.Workshop, name string, namespace string) (subscription *olmv1alpha1.Subscription, err error) {
+	subscription = &olmv1alpha1.Subscription{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, subscription)
+	if err != nil {
+		logrus.Errorf("Failed to get %s subscription: %s", name, err)
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (r *ReconcileWorkshop) GetEffectiveInstallPlan(instance *openshiftv1alpha1.Workshop, name string, namespace string) (installPlan *olmv1alpha1.InstallPlan, err error) {
+	installPlan = &olmv1alpha1.InstallPlan{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, installPlan)
+	if err != nil {
+		logrus.Errorf("Failed to get %s install plan: %s", name, err)
+		return nil, err
+	}
+	return installPlan, nil
+}
+
+func (r *ReconcileWorkshop) GetEffectiveCheCluster(instance *openshiftv1alpha1.Workshop, name string, namespace string) (cheCluster *checlusterv1.CheCluster, err error) {
+	cheCluster = &checlusterv1.CheCluster{}
+	err = r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, cheCluster)
+	if err != nil {
+		logrus.Errorf("Failed to get %s che cluster: %s", name, err)
+		return nil, err
+	}
+	return cheCluster, nil
+}
+
 func (r *ReconcileWorkshop) GetCR(request reconcile.Request) (instance *openshiftv1alpha1.Workshop, err error) {
 	err = r.client.Get(context.TODO(), request.NamespacedName, instance)
 	if err != nil {
@@ -0,0 +1,168 @@
+package workshop
+
+import (
+	"context"
+	"reflect"
+
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// addGitea deploys Gitea, with PostgreSQL or SQLite, in place of the Gogs Operator
+func (r *ReconcileWorkshop) addGitea(instance *openshiftv1alpha1.Workshop, users int) (reconcile.Result, error) {
+	giteaSpec := instance.Spec.Infrastructure.GitServer.Gitea
+
+	volumeSize := giteaSpec.VolumeSize
+	if volumeSize == "" {
+		volumeSize = "4Gi"
+	}
+
+	postgresqlVolumeSize := giteaSpec.PostgresqlVolumeSize
+	if postgresqlVolumeSize == "" {
+		postgresqlVolumeSize = "4Gi"
+	}
+
+	if giteaSpec.Database != "sqlite" {
+		databasePassword, err := util.GeneratePassword(16)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		databaseCredentials := map[string]string{
+			"database-name":     "gitea",
+			"database-password": databasePassword,
+			"database-user":     "gitea",
+		}
+		giteaDatabaseSecret := deployment.NewSecretStringData(instance, "gitea-postgresql", instance.Namespace, databaseCredentials)
+		if err := r.client.Create(context.TODO(), giteaDatabaseSecret); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			logrus.Infof("Created %s Secret", giteaDatabaseSecret.Name)
+		}
+
+		giteaDatabasePersistentVolumeClaim := deployment.NewPersistentVolumeClaim(instance, "gitea-postgresql", instance.Namespace, postgresqlVolumeSize)
+		if err := r.client.Create(context.TODO(), giteaDatabasePersistentVolumeClaim); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			logrus.Infof("Created %s Persistent Volume Claim", giteaDatabasePersistentVolumeClaim.Name)
+		}
+
+		giteaDatabaseDeployment := deployment.NewGiteaDatabaseDeployment(instance, "gitea-postgresql", instance.Namespace)
+		if err := r.client.Create(context.TODO(), giteaDatabaseDeployment); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			logrus.Infof("Created %s Database", giteaDatabaseDeployment.Name)
+		}
+
+		giteaDatabaseService := deployment.NewService(instance, "gitea-postgresql", instance.Namespace, []string{"postgresql"}, []int32{5432})
+		if err := r.client.Create(context.TODO(), giteaDatabaseService); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			logrus.Infof("Created %s Service", giteaDatabaseService.Name)
+		}
+	}
+
+	secretKey, err := util.GeneratePassword(32)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	giteaSecret := deployment.NewSecretStringData(instance, "gitea", instance.Namespace, map[string]string{
+		"secret-key": secretKey,
+	})
+	if err := r.client.Create(context.TODO(), giteaSecret); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Secret", giteaSecret.Name)
+	}
+
+	adminPassword, err := util.GeneratePassword(16)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	giteaAdminSecret := deployment.NewSecretStringData(instance, "gitea-admin", instance.Namespace, map[string]string{
+		"username": "gogsadmin",
+		"password": adminPassword,
+	})
+	if err := r.client.Create(context.TODO(), giteaAdminSecret); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Secret", giteaAdminSecret.Name)
+	}
+
+	giteaPersistentVolumeClaim := deployment.NewPersistentVolumeClaim(instance, "gitea", instance.Namespace, volumeSize)
+	if err := r.client.Create(context.TODO(), giteaPersistentVolumeClaim); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Persistent Volume Claim", giteaPersistentVolumeClaim.Name)
+	}
+
+	giteaService := deployment.NewService(instance, "gitea", instance.Namespace, []string{"http"}, []int32{3000})
+	if err := r.client.Create(context.TODO(), giteaService); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Service", giteaService.Name)
+	}
+
+	giteaRoute := deployment.NewRoute(instance, "gitea", instance.Namespace, "gitea", 3000)
+	if err := r.client.Create(context.TODO(), giteaRoute); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Route", giteaRoute.Name)
+	}
+
+	// Gitea renders the clone URLs with the host given to the route, reported as waiting until it is admitted
+	routeURL, admitted, err := r.getAdmittedRouteURL(giteaRoute.Name, instance.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	} else if !admitted {
+		return r.addGitServerUsers(instance, users, giteaRoute.Name)
+	}
+
+	giteaDeployment := deployment.NewGiteaDeployment(instance, "gitea", instance.Namespace, routeURL+"/")
+	if err := r.client.Create(context.TODO(), giteaDeployment); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s Deployment", giteaDeployment.Name)
+	} else if errors.IsAlreadyExists(err) {
+		giteaDeploymentFound := &appsv1.Deployment{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: giteaDeployment.Name, Namespace: instance.Namespace}, giteaDeploymentFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		// Changing the template, e.g. ROOT_URL when the route host changes, rolls Gitea out
+		desiredPodSpec := &giteaDeployment.Spec.Template.Spec
+		foundPodSpec := &giteaDeploymentFound.Spec.Template.Spec
+		if !sameGiteaContainers(foundPodSpec.Containers, desiredPodSpec.Containers) ||
+			!sameGiteaContainers(foundPodSpec.InitContainers, desiredPodSpec.InitContainers) {
+			foundPodSpec.Containers[0].Image = desiredPodSpec.Containers[0].Image
+			foundPodSpec.Containers[0].Env = desiredPodSpec.Containers[0].Env
+			foundPodSpec.InitContainers = desiredPodSpec.InitContainers
+			if err := r.client.Update(context.TODO(), giteaDeploymentFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			logrus.Infof("Updated %s Deployment", giteaDeploymentFound.Name)
+		}
+	}
+
+	return r.addGitServerUsers(instance, users, giteaRoute.Name)
+}
+
+// sameGiteaContainers compares the fields set by NewGiteaDeployment, the others are defaulted by the API server
+func sameGiteaContainers(found []corev1.Container, desired []corev1.Container) bool {
+	if len(found) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		if found[i].Image != desired[i].Image ||
+			!reflect.DeepEqual(found[i].Command, desired[i].Command) ||
+			!reflect.DeepEqual(found[i].Env, desired[i].Env) {
+			return false
+		}
+	}
+	return true
+}
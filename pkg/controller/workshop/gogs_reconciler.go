@@ -2,30 +2,63 @@ package workshop
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strings"
+	"time"
 
 	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/gogs"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
 	gogscustomresource "github.com/redhat/openshift-workshop-operator/pkg/customresource/gogs"
 	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
+	"github.com/redhat/openshift-workshop-operator/pkg/util"
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // Reconciling Gogs
-func (r *ReconcileWorkshop) reconcileGogs(instance *openshiftv1alpha1.Workshop) (reconcile.Result, error) {
+func (r *ReconcileWorkshop) reconcileGogs(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) (reconcile.Result, error) {
 	enabledGogs := instance.Spec.Infrastructure.Gogs.Enabled
 
 	if enabledGogs {
-		if result, err := r.addGogs(instance); err != nil {
-			return result, err
+		gitServerType := instance.Spec.Infrastructure.GitServer.Type
+		if !isValidGitServerType(gitServerType) {
+			message := fmt.Sprintf("Unknown git server type %s, expected %s or %s", gitServerType, util.Gogs, util.Gitea)
+			logrus.Errorf("%s", message)
+			return reconcile.Result{}, r.updateCondition(instance, openshiftv1alpha1.GitServerTypeValid, corev1.ConditionFalse, "UnknownType", message)
 		}
+		if err := r.updateCondition(instance, openshiftv1alpha1.GitServerTypeValid, corev1.ConditionTrue, "", ""); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		if isGitea(instance) {
+			return r.addGitea(instance, users)
+		}
+		return r.addGogs(instance, users)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileWorkshop) addGogs(instance *openshiftv1alpha1.Workshop) (reconcile.Result, error) {
+// isValidGitServerType returns whether the git server type is empty, gogs or gitea, whatever the case
+func isValidGitServerType(gitServerType string) bool {
+	return gitServerType == "" || strings.EqualFold(gitServerType, util.Gogs) || strings.EqualFold(gitServerType, util.Gitea)
+}
+
+// isGitea returns whether Gitea is deployed as the git server instead of Gogs
+func isGitea(instance *openshiftv1alpha1.Workshop) bool {
+	return strings.EqualFold(instance.Spec.Infrastructure.GitServer.Type, util.Gitea)
+}
+
+func (r *ReconcileWorkshop) addGogs(instance *openshiftv1alpha1.Workshop, users int) (reconcile.Result, error) {
 
 	imageName := instance.Spec.Infrastructure.Gogs.Image.Name
 	imageTag := instance.Spec.Infrastructure.Gogs.Image.Tag
@@ -72,6 +105,282 @@ func (r *ReconcileWorkshop) addGogs(instance *openshiftv1alpha1.Workshop) (recon
 		logrus.Infof("Created %s Custom Resource", gogsCustomResource.Name)
 	}
 
+	gogsCustomResourceFound := &gogscustomresource.Gogs{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: gogsCustomResource.Name, Namespace: instance.Namespace}, gogsCustomResourceFound); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if gogscustomresource.UpdateCustomResource(gogsCustomResourceFound, gogsCustomResource) {
+		if err := r.client.Update(context.TODO(), gogsCustomResourceFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Updated %s Custom Resource", gogsCustomResourceFound.Name)
+	}
+
+	return r.addGitServerUsers(instance, users, gogscustomresource.GetServiceName(instance, gogsCustomResource.Name))
+}
+
+// addGitServerUsers waits for the route of the git server to be admitted and the server to answer,
+// reports it in the status, then seeds the accounts of the users
+func (r *ReconcileWorkshop) addGitServerUsers(instance *openshiftv1alpha1.Workshop, users int, routeName string) (reconcile.Result, error) {
+
+	gogsURL, ready, err := r.getAdmittedRouteURL(routeName, instance.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	transport, err := r.newHTTPTransport(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	gogsClient := gogs.New(gogsURL, transport)
+	gogsStatus := "Available"
+	var adminClient *gogs.Client
+	var adminStatus string
+	if !ready {
+		gogsStatus = "Waiting for route"
+	} else if err := gogsClient.Ping(context.TODO()); err != nil {
+		logrus.Infof("Waiting for the git server to be available: %v", err)
+		gogsStatus = "Unavailable"
+	} else {
+		adminClient, adminStatus, err = r.getGogsAdminClient(context.TODO(), instance, gogsClient)
+		if err != nil {
+			return reconcile.Result{}, err
+		} else if adminStatus != "" {
+			gogsStatus = adminStatus
+		}
+	}
+
+	if instance.Status.Gogs != gogsStatus || instance.Status.GogsURL != gogsURL {
+		instance.Status.Gogs = gogsStatus
+		instance.Status.GogsURL = gogsURL
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if adminStatus != "" {
+		// The administrator must be fixed by hand, see the status
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Minute * 5}, nil
+	} else if gogsStatus != "Available" {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	return r.addGogsUsers(instance, users, gogsClient, adminClient)
+}
+
+// getGogsURL returns the URL of the route exposing the git server, Gogs or Gitea, or an empty string until it exists
+func (r *ReconcileWorkshop) getGogsURL(instance *openshiftv1alpha1.Workshop) (string, error) {
+	if !instance.Spec.Infrastructure.Gogs.Enabled || !isValidGitServerType(instance.Spec.Infrastructure.GitServer.Type) {
+		return "", nil
+	}
+	if isGitea(instance) {
+		return r.getRouteURL("gitea", instance.Namespace)
+	}
+	return r.getRouteURL(gogscustomresource.GetServiceName(instance, "gogs-server"), instance.Namespace)
+}
+
+// getGitServerType returns gogs or gitea when the repository is hosted on the workshop git server,
+// empty otherwise so that the hosting service is guessed from the host
+func (r *ReconcileWorkshop) getGitServerType(instance *openshiftv1alpha1.Workshop, repositoryURL string) (string, error) {
+	gogsURL, err := r.getGogsURL(instance)
+	if err != nil || gogsURL == "" {
+		return "", err
+	}
+	gitServerURL, err := url.Parse(gogsURL)
+	if err != nil {
+		return "", err
+	}
+	gitURL, err := url.Parse(repositoryURL)
+	if err != nil {
+		return "", err
+	}
+	if gitURL.Host != gitServerURL.Host {
+		return "", nil
+	}
+	if isGitea(instance) {
+		return util.Gitea, nil
+	}
+	return util.Gogs, nil
+}
+
+// addGogsUsers creates the Gogs account of each user, with the same password as in OpenShift,
+// and imports the lab repositories into it
+func (r *ReconcileWorkshop) addGogsUsers(instance *openshiftv1alpha1.Workshop, users int, gogsClient *gogs.Client, adminClient *gogs.Client) (reconcile.Result, error) {
+	ctx := context.TODO()
+
+	repositories := getGogsRepositories(instance)
+
+	mustChangePassword := false
+	gogsUsers := []openshiftv1alpha1.GogsUserStatus{}
+	for id := 1; id <= users; id++ {
+		username := fmt.Sprintf("user%d", id)
+
+		user, err := gogsClient.GetUser(ctx, username)
+		if httpclient.IsNotFound(err) {
+			user, err = adminClient.CreateUser(ctx, &gogs.CreateUserOption{
+				LoginName: username,
+				Username:  username,
+				Email:     username + "@none.com",
+				Password:  instance.Spec.User.Password,
+				// Gitea asks for a new password at the first login otherwise
+				MustChangePassword: &mustChangePassword,
+			})
+			if err == nil {
+				logrus.Infof("Created %s Gogs user", username)
+			}
+		}
+		if httpclient.IsStatus(err, http.StatusForbidden) {
+			// Someone else registered first and got the administrator role
+			gogsStatus := fmt.Sprintf("Administrator %s is not an administrator of the git server", adminClient.Username)
+			logrus.Errorf("Error when provisioning %s Gogs user: %s", username, gogsStatus)
+			if instance.Status.Gogs != gogsStatus {
+				instance.Status.Gogs = gogsStatus
+				if err := r.updateStatus(instance); err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+			return reconcile.Result{Requeue: true, RequeueAfter: time.Minute * 5}, nil
+		} else if err != nil {
+			logrus.Errorf("Error when provisioning %s Gogs user: %v", username, err)
+			return reconcile.Result{}, err
+		}
+
+		userStatus := openshiftv1alpha1.GogsUserStatus{User: username}
+		for _, cloneAddr := range repositories {
+			repository, err := r.addGogsRepository(ctx, instance, adminClient, user, cloneAddr)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+			userStatus.Repositories = append(userStatus.Repositories, repository.CloneURL)
+		}
+		gogsUsers = append(gogsUsers, userStatus)
+	}
+
+	if !reflect.DeepEqual(instance.Status.GogsUsers, gogsUsers) {
+		instance.Status.GogsUsers = gogsUsers
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// getGogsAdminClient returns a client authenticated as the git server administrator. Gitea creates it at startup
+// from the gitea-admin Secret. Gogs makes the first registered user the administrator, so it is registered first
+// with a random password stored in the gogs-admin Secret.
+// A problem that needs a manual fix, e.g. a lost Secret or a captcha on the sign up page, is returned as a status
+// with a nil client.
+func (r *ReconcileWorkshop) getGogsAdminClient(ctx context.Context, instance *openshiftv1alpha1.Workshop, gogsClient *gogs.Client) (*gogs.Client, string, error) {
+	gitea := isGitea(instance)
+	adminSecretName := "gogs-admin"
+	if gitea {
+		adminSecretName = "gitea-admin"
+	}
+	username := "gogsadmin"
+
+	adminSecret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: adminSecretName, Namespace: instance.Namespace}, adminSecret); err != nil {
+		// The Secret of Gitea is created with its Deployment
+		if !errors.IsNotFound(err) || gitea {
+			return nil, "", err
+		}
+
+		// A new password would not match the account registered with the lost one
+		if _, err := gogsClient.GetUser(ctx, username); err == nil {
+			return nil, fmt.Sprintf("Administrator %s exists but the %s Secret is missing", username, adminSecretName), nil
+		} else if !httpclient.IsNotFound(err) {
+			return nil, "", err
+		}
+
+		password, err := util.GeneratePassword(16)
+		if err != nil {
+			return nil, "", err
+		}
+		adminSecret = deployment.NewSecretStringData(instance, adminSecretName, instance.Namespace, map[string]string{
+			"username": username,
+			"password": password,
+		})
+		if err := r.client.Create(context.TODO(), adminSecret); err != nil {
+			return nil, "", err
+		}
+		logrus.Infof("Created %s Secret", adminSecret.Name)
+		adminSecret.Data = map[string][]byte{"username": []byte(username), "password": []byte(password)}
+	}
+
+	username = string(adminSecret.Data["username"])
+	password := string(adminSecret.Data["password"])
+
+	if _, err := gogsClient.GetUser(ctx, username); httpclient.IsNotFound(err) && gitea {
+		return nil, fmt.Sprintf("Administrator %s not found, check the logs of the gitea-admin init container", username), nil
+	} else if httpclient.IsNotFound(err) {
+		if err := gogsClient.SignUp(ctx, username, username+"@none.com", password); err != nil {
+			// The sign up form is rendered again with a 200 when it is rejected, e.g. by a captcha
+			logrus.Errorf("Error when registering %s Gogs administrator: %v", username, err)
+			if httpclient.IsStatus(err, http.StatusOK) {
+				return nil, fmt.Sprintf("Registration of administrator %s rejected, check the captcha and registration settings", username), nil
+			}
+			return nil, "", err
+		}
+		logrus.Infof("Registered %s Gogs administrator", username)
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	adminClient := gogsClient.WithBasicAuth(username, password)
+	if _, err := adminClient.GetAuthenticatedUser(ctx); httpclient.IsStatus(err, http.StatusUnauthorized) {
+		logrus.Errorf("Error when authenticating as %s Gogs administrator: %v", username, err)
+		if gitea {
+			// The init container resets the password to the one of the Secret
+			return nil, fmt.Sprintf("Password of administrator %s rejected, restart the gitea Deployment", username), nil
+		}
+		return nil, fmt.Sprintf("Password of administrator %s rejected, check the %s Secret", username, adminSecretName), nil
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	return adminClient, "", nil
+}
+
+// addGogsRepository imports the repository into the account of the user unless it already exists
+func (r *ReconcileWorkshop) addGogsRepository(ctx context.Context, instance *openshiftv1alpha1.Workshop,
+	adminClient *gogs.Client, user *gogs.User, cloneAddr string) (*gogs.Repository, error) {
+
+	repositoryName := getRepositoryName(cloneAddr)
+
+	repository, err := adminClient.GetRepository(ctx, user.Username, repositoryName)
+	if httpclient.IsNotFound(err) {
+		repository, err = adminClient.MigrateRepository(ctx, &gogs.MigrateRepoOption{
+			CloneAddr: cloneAddr,
+			UID:       user.ID,
+			RepoName:  repositoryName,
+			Mirror:    instance.Spec.Infrastructure.Gogs.Mirror,
+		})
+		if err == nil {
+			logrus.Infof("Imported %s into the Gogs account of %s", cloneAddr, user.Username)
+		}
+	}
+	if err != nil {
+		logrus.Errorf("Error when importing %s for %s: %v", cloneAddr, user.Username, err)
+		return nil, err
+	}
+
+	return repository, nil
+}
+
+// getGogsRepositories returns the repositories imported into the account of each user
+func getGogsRepositories(instance *openshiftv1alpha1.Workshop) []string {
+	repositories := instance.Spec.Infrastructure.Gogs.Repositories
+	if len(repositories) == 0 && instance.Spec.Source.GitURL != "" {
+		repositories = []string{instance.Spec.Source.GitURL}
+	}
+	return repositories
+}
+
+// getRepositoryName returns the name of the repository imported from cloneAddr
+func getRepositoryName(cloneAddr string) string {
+	return path.Base(strings.TrimSuffix(strings.TrimSuffix(cloneAddr, "/"), ".git"))
+}
@@ -0,0 +1,321 @@
+package workshop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Reconciling Image Puller
+func (r *ReconcileWorkshop) reconcileImagePuller(instance *openshiftv1alpha1.Workshop, appsHostnameSuffix string) (reconcile.Result, error) {
+	enabledImagePuller := instance.Spec.Infrastructure.ImagePuller.Enabled
+
+	if enabledImagePuller {
+		return r.addImagePuller(instance, appsHostnameSuffix)
+	}
+
+	if err := r.deleteImagePuller(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileWorkshop) addImagePuller(instance *openshiftv1alpha1.Workshop, appsHostnameSuffix string) (reconcile.Result, error) {
+
+	images, ready, err := r.getImagesToPull(instance, appsHostnameSuffix)
+	if err != nil {
+		return reconcile.Result{}, err
+	} else if !ready {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	status := instance.Status.ImagePuller
+	if status == nil || !reflect.DeepEqual(status.Images, images) {
+		status = &openshiftv1alpha1.ImagePullerStatus{Images: images}
+	}
+
+	// Images already pulled everywhere
+	if status.Completed || len(images) == 0 {
+		if err := r.deleteImagePuller(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, r.updateImagePullerStatus(instance, status)
+	}
+
+	imagePullerDaemonSet := deployment.NewImagePullerDaemonSet(instance, "image-puller", instance.Namespace, images)
+	if err := r.client.Create(context.TODO(), imagePullerDaemonSet); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		logrus.Infof("Created %s DaemonSet to pull %d images", imagePullerDaemonSet.Name, len(images))
+	}
+
+	daemonSetFound := &appsv1.DaemonSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: imagePullerDaemonSet.Name, Namespace: instance.Namespace}, daemonSetFound); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !sameImages(daemonSetFound.Spec.Template.Spec.InitContainers, imagePullerDaemonSet.Spec.Template.Spec.InitContainers) {
+		daemonSetFound.Spec.Template.Spec.InitContainers = imagePullerDaemonSet.Spec.Template.Spec.InitContainers
+		if err := r.client.Update(context.TODO(), daemonSetFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Updated %s DaemonSet to pull %d images", daemonSetFound.Name, len(images))
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, r.updateImagePullerStatus(instance, status)
+	}
+
+	// Report the nodes where the pod of the current revision is ready
+	podList := &corev1.PodList{}
+	listOptions := client.InNamespace(instance.Namespace).MatchingLabels(imagePullerDaemonSet.Spec.Selector.MatchLabels)
+	if err := r.client.List(context.TODO(), listOptions, podList); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	status.Nodes = []openshiftv1alpha1.ImagePullerNodeStatus{}
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil ||
+			!sameImages(pod.Spec.InitContainers, imagePullerDaemonSet.Spec.Template.Spec.InitContainers) {
+			continue
+		}
+		status.Nodes = append(status.Nodes, openshiftv1alpha1.ImagePullerNodeStatus{
+			Node:   pod.Spec.NodeName,
+			Pulled: isPodReady(pod),
+		})
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool { return status.Nodes[i].Node < status.Nodes[j].Node })
+
+	desired := int(daemonSetFound.Status.DesiredNumberScheduled)
+	pulled := 0
+	for _, node := range status.Nodes {
+		if node.Pulled {
+			pulled++
+		}
+	}
+	status.Completed = desired > 0 && daemonSetFound.Status.ObservedGeneration >= daemonSetFound.Generation && pulled >= desired
+
+	if err := r.updateImagePullerStatus(instance, status); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !status.Completed {
+		logrus.Infof("Waiting for %d images to be pulled (%d/%d nodes)", len(images), pulled, desired)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	logrus.Infof("Pulled %d images on %d nodes", len(images), pulled)
+	if err := r.deleteImagePuller(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileWorkshop) deleteImagePuller(instance *openshiftv1alpha1.Workshop) error {
+	imagePullerDaemonSet := &appsv1.DaemonSet{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "image-puller", Namespace: instance.Namespace}, imagePullerDaemonSet); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := r.client.Delete(context.TODO(), imagePullerDaemonSet); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	logrus.Infof("Deleted %s DaemonSet", imagePullerDaemonSet.Name)
+
+	return nil
+}
+
+func (r *ReconcileWorkshop) updateImagePullerStatus(instance *openshiftv1alpha1.Workshop, status *openshiftv1alpha1.ImagePullerStatus) error {
+	if reflect.DeepEqual(instance.Status.ImagePuller, status) {
+		return nil
+	}
+	instance.Status.ImagePuller = status
+	return r.updateStatus(instance)
+}
+
+// getImagesToPull returns the sorted images listed in the spec, the devfile and the Che plugins it uses.
+// It is not ready until the Che plugin registry is available.
+func (r *ReconcileWorkshop) getImagesToPull(instance *openshiftv1alpha1.Workshop, appsHostnameSuffix string) ([]string, bool, error) {
+	images := map[string]bool{}
+	for _, image := range instance.Spec.Infrastructure.ImagePuller.Images {
+		images[image] = true
+	}
+
+	if instance.Spec.Infrastructure.Che.Enabled {
+		cheCluster, err := r.GetEffectiveCheCluster(instance, "eclipse-che", "eclipse-che")
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, false, err
+		} else if err != nil || cheCluster.Status.PluginRegistryURL == "" {
+			logrus.Infof("Waiting for the Che plugin registry to list the images to pull")
+			return nil, false, nil
+		}
+
+		transport, err := r.newHTTPTransport(instance)
+		if err != nil {
+			return nil, false, err
+		}
+
+		devfileTemplate, err := r.getDevfileTemplate(instance, transport)
+		if err != nil {
+			return nil, false, err
+		}
+
+		gogsURL, err := r.getGogsURL(instance)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// Images do not depend on the user
+		devfile, err := renderDevfile(devfileTemplate, cheDevfileData{
+			Username:           "user1",
+			ProjectName:        fmt.Sprintf("%s%d", instance.Spec.Infrastructure.Project.Name, 1),
+			GogsURL:            gogsURL,
+			NexusURL:           instance.Status.NexusURL,
+			MavenMirrorURL:     getNexusServiceRepositoryURL(instance, "maven2"),
+			AppsHostnameSuffix: appsHostnameSuffix,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+
+		devfileImages, err := getDevfileImages(devfile, cheCluster.Status.PluginRegistryURL, transport)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, image := range devfileImages {
+			images[image] = true
+		}
+	}
+
+	result := []string{}
+	for image := range images {
+		result = append(result, image)
+	}
+	sort.Strings(result)
+
+	return result, true, nil
+}
+
+// getDevfileImages returns the images of the dockerimage components of the devfile
+// and of the containers of its Che plugins and editors
+func getDevfileImages(devfile string, pluginRegistryURL string, transport http.RoundTripper) ([]string, error) {
+	var (
+		devfileContent struct {
+			Components []struct {
+				Type      string `json:"type"`
+				Image     string `json:"image"`
+				ID        string `json:"id"`
+				Reference string `json:"reference"`
+			} `json:"components"`
+		}
+		images = []string{}
+	)
+
+	if err := json.Unmarshal([]byte(devfile), &devfileContent); err != nil {
+		logrus.Errorf("Error when reading the devfile: %v", err)
+		return nil, err
+	}
+
+	for _, component := range devfileContent.Components {
+		switch component.Type {
+		case "dockerimage":
+			if component.Image != "" {
+				images = append(images, component.Image)
+			}
+		case "chePlugin", "cheEditor":
+			metaURL := component.Reference
+			if metaURL == "" && component.ID != "" {
+				metaURL = strings.TrimSuffix(pluginRegistryURL, "/") + "/v3/plugins/" + component.ID + "/meta.yaml"
+			}
+			if metaURL == "" {
+				continue
+			}
+			pluginImages, err := getPluginImages(metaURL, transport)
+			if err != nil {
+				return nil, err
+			}
+			images = append(images, pluginImages...)
+		}
+	}
+
+	return images, nil
+}
+
+// getPluginImages returns the images of the containers of a plugin from its meta.yaml
+func getPluginImages(metaURL string, transport http.RoundTripper) ([]string, error) {
+	var (
+		metaYAML = []byte{}
+		meta     struct {
+			Spec struct {
+				Containers []struct {
+					Image string `json:"image"`
+				} `json:"containers"`
+				InitContainers []struct {
+					Image string `json:"image"`
+				} `json:"initContainers"`
+			} `json:"spec"`
+		}
+		images = []string{}
+	)
+
+	if _, err := httpclient.New(metaURL, transport).Do(context.TODO(), &httpclient.Request{Method: http.MethodGet}, &metaYAML); err != nil {
+		logrus.Errorf("Error when getting the plugin from %s: %v", metaURL, err)
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(metaYAML, &meta); err != nil {
+		logrus.Errorf("Error when reading the plugin from %s: %v", metaURL, err)
+		return nil, err
+	}
+
+	for _, container := range meta.Spec.Containers {
+		images = append(images, container.Image)
+	}
+	for _, container := range meta.Spec.InitContainers {
+		images = append(images, container.Image)
+	}
+
+	return images, nil
+}
+
+func sameImages(containers []corev1.Container, desired []corev1.Container) bool {
+	if len(containers) != len(desired) {
+		return false
+	}
+	for i := range containers {
+		if containers[i].Image != desired[i].Image {
+			return false
+		}
+	}
+	return true
+}
+
+func isPodReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -2,80 +2,401 @@ package workshop
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
 
 	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
 	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
 	nexus "github.com/redhat/openshift-workshop-operator/pkg/deployment/nexus"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// nexusWarmupChecksumAnnotation records on the warm-up Job the checksum of the containers it runs
+const nexusWarmupChecksumAnnotation = "openshift.workshop/warmup-checksum"
+
 // Reconciling Nexus
-func (r *ReconcileWorkshop) reconcileNexus(instance *openshiftv1alpha1.Workshop) error {
+func (r *ReconcileWorkshop) reconcileNexus(instance *openshiftv1alpha1.Workshop, users int) (reconcile.Result, error) {
 	enabledNexus := instance.Spec.Infrastructure.Nexus.Enabled
 
 	if enabledNexus {
-		if err := r.addNexus(instance); err != nil {
-			return err
-		}
+		return r.addNexus(instance, users)
 	}
 
 	//Success
-	return nil
+	return reconcile.Result{}, nil
 }
 
-func (r *ReconcileWorkshop) addNexus(instance *openshiftv1alpha1.Workshop) error {
+func (r *ReconcileWorkshop) addNexus(instance *openshiftv1alpha1.Workshop, users int) (reconcile.Result, error) {
 	reqLogger := log.WithName("Nexus")
 
-	nexusNamespace := deployment.NewNamespace(instance, "opentlc-shared")
+	nexusOperatorImage := deployment.GetImage(instance.Spec.Infrastructure.Nexus.Image, "quay.io/mcouliba/nexus-operator:v0.10")
+
+	nexusNamespace := deployment.NewNamespace(instance, getNexusNamespace(instance))
 	if err := r.client.Create(context.TODO(), nexusNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		reqLogger.Error(err, "Failed to create Namespace", "Resource.name", nexusNamespace.Name)
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created Nexus Project")
 	}
 
 	nexusCustomResourceDefinition := deployment.NewCustomResourceDefinition(instance, "nexus.gpte.opentlc.com", "gpte.opentlc.com", "Nexus", "NexusList", "nexus", "nexus", "v1alpha1", nil, nil)
 	if err := r.client.Create(context.TODO(), nexusCustomResourceDefinition); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created  Nexus Custom Resource Definition")
 	}
 
 	nexusServiceAccount := deployment.NewServiceAccount(instance, "nexus-operator", nexusNamespace.Name)
 	if err := r.client.Create(context.TODO(), nexusServiceAccount); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created  Nexus Service Account")
 	}
 
 	nexusClusterRole := deployment.NewClusterRole(instance, "nexus-operator", nexusNamespace.Name, nexus.NewRules())
 	if err := r.client.Create(context.TODO(), nexusClusterRole); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created  Nexus Cluster Role")
 	}
 
 	nexusClusterRoleBinding := deployment.NewClusterRoleBindingForServiceAccount(instance, "nexus-operator", nexusNamespace.Name, "nexus-operator", "nexus-operator", "ClusterRole")
 	if err := r.client.Create(context.TODO(), nexusClusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created Nexus Cluster Role Binding")
+	} else {
+		// Bind the service account of the namespace Nexus moved to
+		nexusClusterRoleBindingFound := &rbacv1.ClusterRoleBinding{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: nexusClusterRoleBinding.Name}, nexusClusterRoleBindingFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		if !reflect.DeepEqual(nexusClusterRoleBindingFound.Subjects, nexusClusterRoleBinding.Subjects) {
+			nexusClusterRoleBindingFound.Subjects = nexusClusterRoleBinding.Subjects
+			if err := r.client.Update(context.TODO(), nexusClusterRoleBindingFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			logrus.Infof("Updated %s Cluster Role Binding to the %s namespace", nexusClusterRoleBindingFound.Name, nexusNamespace.Name)
+		}
 	}
 
-	nexusOperator := deployment.NewAnsibleOperatorDeployment(instance, "nexus-operator", nexusNamespace.Name, "quay.io/mcouliba/nexus-operator:v0.10", "nexus-operator")
+	nexusOperator := deployment.NewAnsibleOperatorDeployment(instance, "nexus-operator", nexusNamespace.Name, nexusOperatorImage, "nexus-operator")
 	if err := r.client.Create(context.TODO(), nexusOperator); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created Nexus Operator")
+	} else if errors.IsAlreadyExists(err) {
+		nexusOperatorFound := &appsv1.Deployment{}
+		if err := r.client.Get(context.TODO(), types.NamespacedName{Name: nexusOperator.Name, Namespace: nexusNamespace.Name}, nexusOperatorFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		if !sameImages(nexusOperatorFound.Spec.Template.Spec.Containers, nexusOperator.Spec.Template.Spec.Containers) {
+			for i := range nexusOperatorFound.Spec.Template.Spec.Containers {
+				nexusOperatorFound.Spec.Template.Spec.Containers[i].Image = nexusOperatorImage
+			}
+			if err := r.client.Update(context.TODO(), nexusOperatorFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			logrus.Infof("Updated %s Deployment to %s", nexusOperatorFound.Name, nexusOperatorImage)
+		}
 	}
 
 	nexusCustomResource := nexus.NewCustomResource(instance, "nexus", nexusNamespace.Name)
 	if err := r.client.Create(context.TODO(), nexusCustomResource); err != nil && !errors.IsAlreadyExists(err) {
-		return err
+		return reconcile.Result{}, err
 	} else if err == nil {
 		reqLogger.Info("Created Nexus Custom Resource")
 	}
 
-	//Success
+	nexusCustomResourceFound := &nexus.Nexus{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: nexusCustomResource.Name, Namespace: nexusNamespace.Name}, nexusCustomResourceFound); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if nexus.UpdateCustomResource(nexusCustomResourceFound, nexusCustomResource) {
+		if err := r.client.Update(context.TODO(), nexusCustomResourceFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Updated %s Custom Resource", nexusCustomResourceFound.Name)
+	}
+
+	// Wait for the route created by the Nexus Operator to be admitted and Nexus to answer
+	nexusURL, ready, err := r.getAdmittedRouteURL("nexus", nexusNamespace.Name)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	nexusStatus := "Available"
+	if !ready {
+		nexusStatus = "Waiting for route"
+	} else if err := r.pingNexus(instance, nexusURL); err != nil {
+		logrus.Infof("Waiting for Nexus to be available: %v", err)
+		nexusStatus = "Unavailable"
+	}
+
+	nexusRepositories := getNexusRepositories(nexusURL, &nexusCustomResourceFound.Spec)
+	if instance.Status.Nexus != nexusStatus || instance.Status.NexusURL != nexusURL ||
+		!reflect.DeepEqual(instance.Status.NexusRepositories, nexusRepositories) {
+		instance.Status.Nexus = nexusStatus
+		instance.Status.NexusURL = nexusURL
+		instance.Status.NexusRepositories = nexusRepositories
+		if err := r.updateStatus(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if nexusStatus != "Available" {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	if err := r.addNexusUserSettings(instance, users); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	return r.warmupNexus(instance, nexusNamespace.Name)
+}
+
+// addNexusUserSettings creates in the project of each user the maven-settings ConfigMap
+// and the npmrc Secret pointing at the groups of Nexus
+func (r *ReconcileWorkshop) addNexusUserSettings(instance *openshiftv1alpha1.Workshop, users int) error {
+	if !instance.Spec.Infrastructure.Project.Enabled {
+		return nil
+	}
+
+	mavenGroupURL := getNexusServiceRepositoryURL(instance, "maven2")
+	npmGroupURL := getNexusServiceRepositoryURL(instance, "npm")
+
+	for id := 1; id <= users; id++ {
+		projectName := fmt.Sprintf("%s%d", instance.Spec.Infrastructure.Project.Name, id)
+
+		if mavenGroupURL != "" {
+			mavenSettingsConfigMap := deployment.NewConfigMap(instance, "maven-settings", projectName, map[string]string{
+				"settings.xml": deployment.NewMavenSettings(mavenGroupURL),
+			})
+			if err := r.client.Create(context.TODO(), mavenSettingsConfigMap); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			} else if err == nil {
+				logrus.Infof("Created %s ConfigMap in %s", mavenSettingsConfigMap.Name, projectName)
+			} else {
+				mavenSettingsConfigMapFound := &corev1.ConfigMap{}
+				if err := r.client.Get(context.TODO(), types.NamespacedName{Name: mavenSettingsConfigMap.Name, Namespace: projectName}, mavenSettingsConfigMapFound); err != nil {
+					return err
+				}
+				if !reflect.DeepEqual(mavenSettingsConfigMapFound.Data, mavenSettingsConfigMap.Data) {
+					mavenSettingsConfigMapFound.Data = mavenSettingsConfigMap.Data
+					if err := r.client.Update(context.TODO(), mavenSettingsConfigMapFound); err != nil {
+						return err
+					}
+					logrus.Infof("Updated %s ConfigMap in %s", mavenSettingsConfigMapFound.Name, projectName)
+				}
+			}
+		}
+
+		if npmGroupURL != "" {
+			npmrc := deployment.NewNpmrc(npmGroupURL)
+			npmrcSecret := deployment.NewSecretStringData(instance, "npmrc", projectName, map[string]string{
+				".npmrc": npmrc,
+			})
+			if err := r.client.Create(context.TODO(), npmrcSecret); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			} else if err == nil {
+				logrus.Infof("Created %s Secret in %s", npmrcSecret.Name, projectName)
+			} else {
+				npmrcSecretFound := &corev1.Secret{}
+				if err := r.client.Get(context.TODO(), types.NamespacedName{Name: npmrcSecret.Name, Namespace: projectName}, npmrcSecretFound); err != nil {
+					return err
+				}
+				if string(npmrcSecretFound.Data[".npmrc"]) != npmrc {
+					npmrcSecretFound.Data = map[string][]byte{".npmrc": []byte(npmrc)}
+					if err := r.client.Update(context.TODO(), npmrcSecretFound); err != nil {
+						return err
+					}
+					logrus.Infof("Updated %s Secret in %s", npmrcSecretFound.Name, projectName)
+				}
+			}
+		}
+	}
+
 	return nil
 }
+
+// getNexusServiceRepositoryURL returns the in-cluster URL of the first group of the format reported
+// in the status, or an empty string. Pods use the service as they may not trust the certificate of the route.
+func getNexusServiceRepositoryURL(instance *openshiftv1alpha1.Workshop, format string) string {
+	if !instance.Spec.Infrastructure.Nexus.Enabled {
+		return ""
+	}
+	for _, repository := range instance.Status.NexusRepositories {
+		if repository.Format == format && repository.Type == "group" {
+			return "http://nexus." + getNexusNamespace(instance) + ".svc:8081/repository/" + repository.Name + "/"
+		}
+	}
+	return ""
+}
+
+// warmupNexus runs the warm-up Job once Nexus is available, again when the warm-up spec changes,
+// and reports its outcome. The Job is kept to remember the spec it ran with.
+func (r *ReconcileWorkshop) warmupNexus(instance *openshiftv1alpha1.Workshop, namespace string) (reconcile.Result, error) {
+	warmupSpec := instance.Spec.Infrastructure.Nexus.Warmup
+	if !warmupSpec.Enabled {
+		return reconcile.Result{}, nil
+	}
+
+	clusterProxy, err := r.getClusterProxy()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	httpProxy, httpsProxy, noProxy := "", "", ""
+	if clusterProxy != nil {
+		httpProxy, httpsProxy, noProxy = clusterProxy.Status.HTTPProxy, clusterProxy.Status.HTTPSProxy, clusterProxy.Status.NoProxy
+	}
+
+	warmupJob := deployment.NewNexusWarmupJob(instance, "nexus-warmup", namespace,
+		getNexusServiceRepositoryURL(instance, "maven2"), getNexusServiceRepositoryURL(instance, "npm"),
+		httpProxy, httpsProxy, noProxy)
+	if len(warmupJob.Spec.Template.Spec.Containers) == 0 {
+		return reconcile.Result{}, nil
+	}
+
+	warmupChecksum, err := getChecksum(warmupJob.Spec.Template.Spec.Containers)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	warmupJob.Annotations = map[string]string{nexusWarmupChecksumAnnotation: warmupChecksum}
+
+	warmupJobFound := &batchv1.Job{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: warmupJob.Name, Namespace: namespace}, warmupJobFound); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if err == nil && warmupJobFound.Annotations[nexusWarmupChecksumAnnotation] != warmupChecksum {
+		// Run again with the new spec
+		propagationPolicy := metav1.DeletePropagationBackground
+		if err := r.client.Delete(context.TODO(), warmupJobFound, client.PropagationPolicy(propagationPolicy)); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Deleted %s Job to warm Nexus up again", warmupJobFound.Name)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 5}, r.updateNexusWarmupStatus(instance, nil)
+	} else if errors.IsNotFound(err) {
+		if err := r.client.Create(context.TODO(), warmupJob); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		}
+		logrus.Infof("Created %s Job", warmupJob.Name)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, r.updateNexusWarmupStatus(instance, &openshiftv1alpha1.NexusWarmupStatus{})
+	}
+
+	warmupStatus := &openshiftv1alpha1.NexusWarmupStatus{}
+	if warmupJobFound.Status.Succeeded > 0 {
+		warmupStatus.Completed = true
+		warmupStatus.Succeeded = true
+	}
+	for _, condition := range warmupJobFound.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			warmupStatus.Completed = true
+			warmupStatus.Message = condition.Message
+		}
+	}
+	if warmupStatus.Completed && warmupJobFound.Status.StartTime != nil {
+		completionTime := time.Now()
+		if warmupJobFound.Status.CompletionTime != nil {
+			completionTime = warmupJobFound.Status.CompletionTime.Time
+		}
+		warmupStatus.Duration = completionTime.Sub(warmupJobFound.Status.StartTime.Time).Round(time.Second).String()
+	}
+
+	if err := r.updateNexusWarmupStatus(instance, warmupStatus); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if !warmupStatus.Completed {
+		logrus.Infof("Waiting for the %s Job to complete", warmupJobFound.Name)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileWorkshop) updateNexusWarmupStatus(instance *openshiftv1alpha1.Workshop, status *openshiftv1alpha1.NexusWarmupStatus) error {
+	if reflect.DeepEqual(instance.Status.NexusWarmup, status) {
+		return nil
+	}
+	instance.Status.NexusWarmup = status
+	return r.updateStatus(instance)
+}
+
+// getNexusNamespace returns the namespace in which Nexus is deployed
+func getNexusNamespace(instance *openshiftv1alpha1.Workshop) string {
+	if instance.Spec.Infrastructure.Nexus.Namespace != "" {
+		return instance.Spec.Infrastructure.Nexus.Namespace
+	}
+	return "opentlc-shared"
+}
+
+// pingNexus checks that Nexus can serve requests
+func (r *ReconcileWorkshop) pingNexus(instance *openshiftv1alpha1.Workshop, nexusURL string) error {
+	transport, err := r.newHTTPTransport(instance)
+	if err != nil {
+		return err
+	}
+
+	client := httpclient.New(nexusURL, transport)
+	client.Retries = 0
+	_, err = client.Do(context.TODO(), &httpclient.Request{Method: http.MethodGet, Path: "/service/rest/v1/status"}, nil)
+	return err
+}
+
+// getNexusRepositories returns the URLs of the repositories of the Nexus custom resource
+func getNexusRepositories(nexusURL string, spec *nexus.NexusSpec) []openshiftv1alpha1.NexusRepositoryStatus {
+	if nexusURL == "" {
+		return nil
+	}
+
+	repositoryURL := func(name string) string {
+		return strings.TrimSuffix(nexusURL, "/") + "/repository/" + name + "/"
+	}
+
+	repositories := []openshiftv1alpha1.NexusRepositoryStatus{}
+	for _, repository := range spec.NexusReposMavenProxy {
+		repositories = append(repositories, openshiftv1alpha1.NexusRepositoryStatus{Name: repository.Name, Format: "maven2", Type: "proxy", URL: repositoryURL(repository.Name)})
+	}
+	for _, repository := range spec.NexusReposMavenHosted {
+		repositories = append(repositories, openshiftv1alpha1.NexusRepositoryStatus{Name: repository.Name, Format: "maven2", Type: "hosted", URL: repositoryURL(repository.Name)})
+	}
+	for _, repository := range spec.NexusReposMavenGroup {
+		repositories = append(repositories, openshiftv1alpha1.NexusRepositoryStatus{Name: repository.Name, Format: "maven2", Type: "group", URL: repositoryURL(repository.Name)})
+	}
+	for _, repository := range spec.NexusReposDockerHosted {
+		repositories = append(repositories, openshiftv1alpha1.NexusRepositoryStatus{Name: repository.Name, Format: "docker", Type: "hosted", URL: repositoryURL(repository.Name)})
+	}
+	for _, repository := range spec.NexusReposNpmProxy {
+		repositories = append(repositories, openshiftv1alpha1.NexusRepositoryStatus{Name: repository.Name, Format: "npm", Type: "proxy", URL: repositoryURL(repository.Name)})
+	}
+	for _, repository := range spec.NexusReposNpmGroup {
+		repositories = append(repositories, openshiftv1alpha1.NexusRepositoryStatus{Name: repository.Name, Format: "npm", Type: "group", URL: repositoryURL(repository.Name)})
+	}
+
+	return repositories
+}
+
+// getChecksum returns the SHA-256 checksum of the JSON representation of value
+func getChecksum(value interface{}) (string, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(content)), nil
+}
@@ -0,0 +1,552 @@
+package workshop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	olmv1alpha1 "github.com/operator-framework/operator-lifecycle-manager/pkg/api/apis/operators/v1alpha1"
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
+	"github.com/redhat/openshift-workshop-operator/pkg/deployment/packagemanifest"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newSubscription makes sure the catalog of the component exists and is valid for the
+// requested package, channel and pinned ClusterServiceVersion, and builds its Subscription
+func (r *ReconcileWorkshop) newSubscription(instance *openshiftv1alpha1.Workshop,
+	conditionType openshiftv1alpha1.WorkshopConditionType, name string, namespace string, packageName string,
+	defaultCatalogSource string, operatorHub openshiftv1alpha1.OperatorHubSpec) (*olmv1alpha1.Subscription, error) {
+
+	catalogSource := operatorHub.CatalogSource
+	if catalogSource == "" {
+		catalogSource = defaultCatalogSource
+	}
+	catalogSourceNamespace := operatorHub.CatalogSourceNamespace
+	if catalogSourceNamespace == "" {
+		catalogSourceNamespace = "openshift-marketplace"
+	}
+
+	if operatorHub.CatalogSourceImage != "" {
+		// The default catalogs are owned by the marketplace operator, the mirror needs its own name
+		if operatorHub.CatalogSource == "" || isDefaultCatalogSource(operatorHub.CatalogSource) {
+			message := fmt.Sprintf("Catalog source image %s requires a catalogSource name other than the default catalogs", operatorHub.CatalogSourceImage)
+			return nil, r.catalogError(instance, conditionType, "InvalidCatalogSource", message)
+		}
+		if err := r.addUpdateCatalogSource(instance, catalogSource, catalogSourceNamespace, operatorHub.CatalogSourceImage); err != nil {
+			return nil, err
+		}
+	}
+
+	// Once installed, the catalog may have pruned the pinned ClusterServiceVersion
+	startingCSV, installed, err := r.getInstalledStartingCSV(name, namespace, catalogSource, catalogSourceNamespace, operatorHub)
+	if err != nil {
+		return nil, err
+	}
+	if !installed {
+		startingCSV, err = r.getResolvedStartingCSV(instance, conditionType, name, namespace, catalogSource, catalogSourceNamespace, packageName, operatorHub)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return deployment.NewSubscription(instance, deployment.NewSubscriptionParameters{
+		Name:                   name,
+		Namespace:              namespace,
+		PackageName:            packageName,
+		CatalogSource:          catalogSource,
+		CatalogSourceNamespace: catalogSourceNamespace,
+		Channel:                operatorHub.Channel,
+		StartingCSV:            startingCSV,
+		InstallPlanApproval:    operatorHub.InstallPlanApproval,
+	}), nil
+}
+
+// isDefaultCatalogSource returns whether the catalog is one of the catalogs shipped with OpenShift
+func isDefaultCatalogSource(name string) bool {
+	switch name {
+	case "redhat-operators", "certified-operators", "community-operators", "redhat-marketplace":
+		return true
+	}
+	return false
+}
+
+// addUpdateCatalogSource creates the mirrored CatalogSource or updates its index image
+func (r *ReconcileWorkshop) addUpdateCatalogSource(instance *openshiftv1alpha1.Workshop, name string, namespace string, image string) error {
+	catalogSource := deployment.NewCatalogSource(instance, name, namespace, image)
+	if err := r.client.Create(context.TODO(), catalogSource); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	} else if err == nil {
+		logrus.Infof("Created %s CatalogSource", catalogSource.Name)
+		return nil
+	}
+
+	catalogSourceFound := &olmv1alpha1.CatalogSource{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, catalogSourceFound); err != nil {
+		return err
+	}
+
+	if catalogSourceFound.Spec.Image != image {
+		catalogSourceFound.Spec.Image = image
+		if err := r.client.Update(context.TODO(), catalogSourceFound); err != nil {
+			return err
+		}
+		logrus.Infof("Updated %s CatalogSource to %s", name, image)
+	}
+
+	return nil
+}
+
+// getInstalledStartingCSV returns the starting CSV of the existing Subscription and true when
+// its ClusterServiceVersion is installed and the Workshop still asks for the same catalog, channel and pin.
+// A pin ahead of the installed ClusterServiceVersion with Manual approval is resolved again,
+// its upgrade path is read from the channel.
+func (r *ReconcileWorkshop) getInstalledStartingCSV(name string, namespace string, catalogSource string,
+	catalogSourceNamespace string, operatorHub openshiftv1alpha1.OperatorHubSpec) (string, bool, error) {
+
+	subscription := &olmv1alpha1.Subscription{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, subscription); err != nil {
+		if errors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	if subscription.Spec == nil || subscription.Status.InstalledCSV == "" ||
+		subscription.Spec.CatalogSource != catalogSource ||
+		subscription.Spec.CatalogSourceNamespace != catalogSourceNamespace ||
+		subscription.Spec.Channel != operatorHub.Channel {
+		return "", false, nil
+	}
+
+	pinnedCSV := operatorHub.ClusterServiceVersion
+	if pinnedCSV != "" && pinnedCSV != subscription.Spec.StartingCSV {
+		return "", false, nil
+	}
+	if pinnedCSV != "" && pinnedCSV != subscription.Status.InstalledCSV &&
+		subscription.Spec.InstallPlanApproval == olmv1alpha1.ApprovalManual {
+		return "", false, nil
+	}
+	return pinnedCSV, true, nil
+}
+
+// resolvedCSVTTL is how long the resolution of a Subscription is kept before the package server
+// is read again, e.g. to notice an update of the catalog
+const resolvedCSVTTL = 10 * time.Minute
+
+// resolvedCSV is the ClusterServiceVersion resolved for a request of the Workshop, with the entries
+// of the channel from its head, nil when the package server does not report them
+type resolvedCSV struct {
+	request     string
+	startingCSV string
+	entries     []string
+	resolvedAt  time.Time
+}
+
+// getResolvedStartingCSV returns the ClusterServiceVersion to subscribe to, only resolved again against
+// the package server when the request changes or the last resolution is older than resolvedCSVTTL
+func (r *ReconcileWorkshop) getResolvedStartingCSV(instance *openshiftv1alpha1.Workshop,
+	conditionType openshiftv1alpha1.WorkshopConditionType, name string, namespace string, catalogSource string,
+	catalogSourceNamespace string, packageName string, operatorHub openshiftv1alpha1.OperatorHubSpec) (string, error) {
+
+	key := namespace + "/" + name
+	request := strings.Join([]string{catalogSource, catalogSourceNamespace, operatorHub.CatalogSourceImage, packageName,
+		operatorHub.Channel, operatorHub.ClusterServiceVersion, strconv.FormatBool(operatorHub.FallbackToChannelHead)}, "\x00")
+
+	r.resolvedCSVsLock.Lock()
+	resolved, found := r.resolvedCSVs[key]
+	r.resolvedCSVsLock.Unlock()
+	if found && resolved.request == request && time.Since(resolved.resolvedAt) < resolvedCSVTTL {
+		return resolved.startingCSV, nil
+	}
+
+	startingCSV, entries, err := r.resolveStartingCSV(instance, conditionType, catalogSource, catalogSourceNamespace, packageName, operatorHub)
+	if err != nil {
+		return "", err
+	}
+
+	r.resolvedCSVsLock.Lock()
+	defer r.resolvedCSVsLock.Unlock()
+	if r.resolvedCSVs == nil {
+		r.resolvedCSVs = map[string]resolvedCSV{}
+	}
+	r.resolvedCSVs[key] = resolvedCSV{request: request, startingCSV: startingCSV, entries: entries, resolvedAt: time.Now()}
+	return startingCSV, nil
+}
+
+// getChannelEntries returns the entries of the channel last resolved for the Subscription, or nil
+func (r *ReconcileWorkshop) getChannelEntries(name string, namespace string) []string {
+	r.resolvedCSVsLock.Lock()
+	defer r.resolvedCSVsLock.Unlock()
+	return r.resolvedCSVs[namespace+"/"+name].entries
+}
+
+// getUpgradePath returns the ClusterServiceVersions installed one after the other to go from the installed one
+// to the pinned one, given the entries of the channel from its head, and whether the pin is ahead of the installed one.
+// Only the pin is known without the entries.
+func getUpgradePath(entries []string, installedCSV string, pinnedCSV string) (map[string]bool, bool) {
+	path := map[string]bool{pinnedCSV: true}
+	pinned, installed := -1, -1
+	for i, entry := range entries {
+		if entry == pinnedCSV {
+			pinned = i
+		}
+		if entry == installedCSV {
+			installed = i
+		}
+	}
+	if pinned < 0 || installed < 0 || pinned >= installed {
+		return path, false
+	}
+	for i := pinned; i < installed; i++ {
+		path[entries[i]] = true
+	}
+	return path, true
+}
+
+// resolveStartingCSV checks the PackageManifest of the catalog for the requested package,
+// channel and pinned ClusterServiceVersion, reports the result in the given condition
+// and returns the ClusterServiceVersion to subscribe to with the entries of the channel
+func (r *ReconcileWorkshop) resolveStartingCSV(instance *openshiftv1alpha1.Workshop,
+	conditionType openshiftv1alpha1.WorkshopConditionType, catalogSource string, catalogSourceNamespace string,
+	packageName string, operatorHub openshiftv1alpha1.OperatorHubSpec) (string, []string, error) {
+
+	packageManifest, err := r.getPackageManifest(catalogSource, catalogSourceNamespace, packageName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if packageManifest == nil {
+		message := fmt.Sprintf("Package %s not found in %s/%s catalog", packageName, catalogSourceNamespace, catalogSource)
+		return "", nil, r.catalogError(instance, conditionType, "PackageNotFound", message)
+	}
+
+	var channel *packagemanifest.PackageChannel
+	for i := range packageManifest.Status.Channels {
+		if packageManifest.Status.Channels[i].Name == operatorHub.Channel {
+			channel = &packageManifest.Status.Channels[i]
+			break
+		}
+	}
+
+	if channel == nil {
+		message := fmt.Sprintf("Channel %s not found for package %s (available: %s)",
+			operatorHub.Channel, packageName, availableVersions(packageManifest))
+		return "", nil, r.catalogError(instance, conditionType, "ChannelNotFound", message)
+	}
+
+	startingCSV := operatorHub.ClusterServiceVersion
+	found, verifiable := channelContainsCSV(channel, startingCSV)
+	if startingCSV != "" && !verifiable {
+		// Let OLM resolve the pin against the catalog
+		logrus.Infof("Package server does not list the entries of channel %s, %s can not be verified", channel.Name, startingCSV)
+		if err := r.updateCondition(instance, conditionType, corev1.ConditionTrue, "ClusterServiceVersionUnverified",
+			fmt.Sprintf("Package %s is available in channel %s (head: %s), the entries of the channel are not reported to verify %s",
+				packageName, channel.Name, channel.CurrentCSV, startingCSV)); err != nil {
+			return "", nil, err
+		}
+		return startingCSV, nil, nil
+	}
+
+	if startingCSV != "" && !found {
+		message := fmt.Sprintf("ClusterServiceVersion %s not found in channel %s of package %s (available: %s)",
+			startingCSV, channel.Name, packageName, availableVersions(packageManifest))
+
+		if !operatorHub.FallbackToChannelHead {
+			return "", nil, r.catalogError(instance, conditionType, "ClusterServiceVersionNotFound", message)
+		}
+
+		logrus.Warnf("%s, falling back to %s", message, channel.CurrentCSV)
+		if err := r.updateCondition(instance, conditionType, corev1.ConditionTrue, "FallbackToChannelHead",
+			fmt.Sprintf("%s, subscribed to %s instead", message, channel.CurrentCSV)); err != nil {
+			return "", nil, err
+		}
+		return channel.CurrentCSV, channelEntries(channel), nil
+	}
+
+	if err := r.updateCondition(instance, conditionType, corev1.ConditionTrue, "ClusterServiceVersionFound",
+		fmt.Sprintf("Package %s is available in channel %s (head: %s)", packageName, channel.Name, channel.CurrentCSV)); err != nil {
+		return "", nil, err
+	}
+	return startingCSV, channelEntries(channel), nil
+}
+
+func (r *ReconcileWorkshop) getPackageManifest(catalogSource string, catalogSourceNamespace string,
+	packageName string) (*packagemanifest.PackageManifest, error) {
+
+	packageManifestList := &packagemanifest.PackageManifestList{}
+	listOptions := &client.ListOptions{}
+	listOptions.InNamespace(catalogSourceNamespace)
+	listOptions.MatchingLabels(map[string]string{"catalog": catalogSource})
+	if err := r.client.List(context.TODO(), listOptions, packageManifestList); err != nil {
+		logrus.Errorf("Failed to list package manifests of %s catalog: %s", catalogSource, err)
+		return nil, err
+	}
+
+	for i := range packageManifestList.Items {
+		if packageManifestList.Items[i].Status.PackageName == packageName {
+			return &packageManifestList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *ReconcileWorkshop) catalogError(instance *openshiftv1alpha1.Workshop,
+	conditionType openshiftv1alpha1.WorkshopConditionType, reason string, message string) error {
+	logrus.Error(message)
+	if err := r.updateCondition(instance, conditionType, corev1.ConditionFalse, reason, message); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s", message)
+}
+
+// channelContainsCSV returns whether the channel contains the ClusterServiceVersion and whether
+// it could be verified. Package servers before OpenShift 4.4 do not report the channel entries,
+// only the head of the channel is known then.
+func channelContainsCSV(channel *packagemanifest.PackageChannel, csv string) (bool, bool) {
+	if channel.CurrentCSV == csv {
+		return true, true
+	}
+	for _, entry := range channel.Entries {
+		if entry.Name == csv {
+			return true, true
+		}
+	}
+	return false, len(channel.Entries) > 0
+}
+
+// channelEntries returns the names of the entries of the channel from its head
+func channelEntries(channel *packagemanifest.PackageChannel) []string {
+	entries := []string{}
+	for _, entry := range channel.Entries {
+		entries = append(entries, entry.Name)
+	}
+	return entries
+}
+
+func availableVersions(packageManifest *packagemanifest.PackageManifest) string {
+	versions := []string{}
+	for _, channel := range packageManifest.Status.Channels {
+		csvs := []string{channel.CurrentCSV}
+		for _, entry := range channel.Entries {
+			if entry.Name != channel.CurrentCSV {
+				csvs = append(csvs, entry.Name)
+			}
+		}
+		versions = append(versions, fmt.Sprintf("%s: %s", channel.Name, strings.Join(csvs, ", ")))
+	}
+	return strings.Join(versions, "; ")
+}
+
+// addUpdateSubscription creates the Subscription or updates its channel, catalog,
+// starting CSV and approval when they changed in the Workshop
+func (r *ReconcileWorkshop) addUpdateSubscription(subscription *olmv1alpha1.Subscription) error {
+	if err := r.client.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	} else if err == nil {
+		logrus.Infof("Created %s Subscription", subscription.Name)
+		return nil
+	}
+
+	subscriptionFound := &olmv1alpha1.Subscription{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: subscription.Name, Namespace: subscription.Namespace}, subscriptionFound); err != nil {
+		return err
+	}
+
+	if subscriptionFound.Spec == nil ||
+		subscriptionFound.Spec.Channel != subscription.Spec.Channel ||
+		subscriptionFound.Spec.StartingCSV != subscription.Spec.StartingCSV ||
+		subscriptionFound.Spec.InstallPlanApproval != subscription.Spec.InstallPlanApproval ||
+		subscriptionFound.Spec.CatalogSource != subscription.Spec.CatalogSource ||
+		subscriptionFound.Spec.CatalogSourceNamespace != subscription.Spec.CatalogSourceNamespace {
+		subscriptionFound.Spec = subscription.Spec
+		if err := r.client.Update(context.TODO(), subscriptionFound); err != nil {
+			return err
+		}
+		logrus.Infof("Updated %s Subscription (channel: %s, startingCSV: %s, approval: %s)", subscription.Name,
+			subscription.Spec.Channel, subscription.Spec.StartingCSV, subscription.Spec.InstallPlanApproval)
+	}
+
+	return nil
+}
+
+// isOperatorInstalled follows the Subscription to its installed ClusterServiceVersion,
+// approves the pending InstallPlans on the path to the pinned CSV when approval is Manual,
+// reports the CSV phase, available upgrades, InstallPlans left to approve and any InstallPlan failure
+// in the Workshop status and returns whether the CSV reached the Succeeded phase and the pin
+func (r *ReconcileWorkshop) isOperatorInstalled(instance *openshiftv1alpha1.Workshop, desired *olmv1alpha1.Subscription) (bool, error) {
+	subscription, err := r.GetEffectiveSubscription(instance, desired.Name, desired.Namespace)
+	if err != nil {
+		return false, err
+	}
+
+	operatorStatus := openshiftv1alpha1.OperatorStatus{
+		Subscription: subscription.Name,
+		Namespace:    subscription.Namespace,
+		CurrentCSV:   subscription.Status.CurrentCSV,
+		InstalledCSV: subscription.Status.InstalledCSV,
+	}
+
+	pinnedCSV := desired.Spec.StartingCSV
+	entries := r.getChannelEntries(desired.Name, desired.Namespace)
+	upgradePath, pinAhead := getUpgradePath(entries, subscription.Status.InstalledCSV, pinnedCSV)
+
+	// OLM creates one InstallPlan per step of the upgrade, the Subscription only references the last one
+	installPlans, err := r.getSubscriptionInstallPlans(subscription)
+	if err != nil {
+		return false, err
+	}
+	pendingInstallPlans := []string{}
+	for i := range installPlans {
+		installPlan := &installPlans[i]
+		if approved, err := r.approveInstallPlan(installPlan, pinnedCSV, upgradePath); err != nil {
+			return false, err
+		} else if !approved && installPlan.Status.Phase == olmv1alpha1.InstallPlanPhaseRequiresApproval && !installPlan.Spec.Approved {
+			pendingInstallPlans = append(pendingInstallPlans, fmt.Sprintf("%s (%s)",
+				installPlan.Name, strings.Join(installPlan.Spec.ClusterServiceVersionNames, ", ")))
+		}
+	}
+	sort.Strings(pendingInstallPlans)
+	operatorStatus.PendingInstallPlans = strings.Join(pendingInstallPlans, "; ")
+
+	if subscription.Status.InstallPlanRef != nil {
+		installPlan, err := r.GetEffectiveInstallPlan(instance, subscription.Status.InstallPlanRef.Name, subscription.Status.InstallPlanRef.Namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		if installPlan != nil {
+			operatorStatus.InstallPlan = installPlan.Name
+			operatorStatus.InstallPlanPhase = string(installPlan.Status.Phase)
+			if installPlan.Status.Phase == olmv1alpha1.InstallPlanPhaseRequiresApproval && !installPlan.Spec.Approved {
+				operatorStatus.AvailableUpgrade = strings.Join(installPlan.Spec.ClusterServiceVersionNames, ", ")
+			}
+			if installPlan.Status.Phase == olmv1alpha1.InstallPlanPhaseFailed {
+				for _, condition := range installPlan.Status.Conditions {
+					if condition.Status == corev1.ConditionFalse {
+						operatorStatus.Message = fmt.Sprintf("InstallPlan %s failed: %s", installPlan.Name, condition.Message)
+					}
+				}
+			}
+		}
+	}
+
+	if operatorStatus.AvailableUpgrade == "" && subscription.Status.State == olmv1alpha1.SubscriptionStateUpgradeAvailable {
+		operatorStatus.AvailableUpgrade = subscription.Status.CurrentCSV
+	}
+
+	// With Manual approval the upgrade to a pin ahead of the installed CSV goes one InstallPlan at a time
+	upgrading := pinnedCSV != "" && subscription.Status.InstalledCSV != "" && subscription.Status.InstalledCSV != pinnedCSV &&
+		subscription.Spec != nil && subscription.Spec.InstallPlanApproval == olmv1alpha1.ApprovalManual
+	if upgrading && len(entries) > 0 {
+		// The pin may be behind the installed CSV
+		upgrading = pinAhead
+	} else if upgrading {
+		upgrading = subscription.Status.State != olmv1alpha1.SubscriptionStateAtLatest
+	}
+	if upgrading && len(pendingInstallPlans) > 0 && operatorStatus.Message == "" {
+		operatorStatus.Message = fmt.Sprintf("Upgrade from %s to %s blocked by InstallPlans not on its path: %s",
+			subscription.Status.InstalledCSV, pinnedCSV, operatorStatus.PendingInstallPlans)
+		logrus.Warn(operatorStatus.Message)
+	}
+
+	installed := false
+	if subscription.Status.InstalledCSV != "" {
+		clusterServiceVersion, err := r.GetEffectiveCSV(instance, subscription.Status.InstalledCSV, subscription.Namespace)
+		if err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+		if clusterServiceVersion != nil {
+			operatorStatus.Phase = string(clusterServiceVersion.Status.Phase)
+			if clusterServiceVersion.Status.Phase == olmv1alpha1.CSVPhaseFailed {
+				operatorStatus.Message = fmt.Sprintf("ClusterServiceVersion %s failed: %s",
+					clusterServiceVersion.Name, clusterServiceVersion.Status.Message)
+			}
+			installed = clusterServiceVersion.Status.Phase == olmv1alpha1.CSVPhaseSucceeded
+		}
+	}
+
+	if err := r.updateOperatorStatus(instance, operatorStatus); err != nil {
+		return false, err
+	}
+
+	if !installed {
+		logrus.Infof("Waiting for %s ClusterServiceVersion %s (phase: %s)", subscription.Name,
+			subscription.Status.InstalledCSV, operatorStatus.Phase)
+	} else if upgrading && len(pendingInstallPlans) == 0 {
+		// A blocked upgrade is reported and the installed version kept running
+		logrus.Infof("Waiting for %s to upgrade from %s to %s", subscription.Name, subscription.Status.InstalledCSV, pinnedCSV)
+		return false, nil
+	}
+	return installed, nil
+}
+
+// getSubscriptionInstallPlans returns the InstallPlans created by OLM for the Subscription
+func (r *ReconcileWorkshop) getSubscriptionInstallPlans(subscription *olmv1alpha1.Subscription) ([]olmv1alpha1.InstallPlan, error) {
+	installPlanList := &olmv1alpha1.InstallPlanList{}
+	listOptions := &client.ListOptions{}
+	listOptions.InNamespace(subscription.Namespace)
+	if err := r.client.List(context.TODO(), listOptions, installPlanList); err != nil {
+		logrus.Errorf("Failed to list install plans of %s: %s", subscription.Namespace, err)
+		return nil, err
+	}
+
+	installPlans := []olmv1alpha1.InstallPlan{}
+	for _, installPlan := range installPlanList.Items {
+		for _, owner := range installPlan.OwnerReferences {
+			if owner.Kind == olmv1alpha1.SubscriptionKind && owner.Name == subscription.Name {
+				installPlans = append(installPlans, installPlan)
+				break
+			}
+		}
+	}
+	return installPlans, nil
+}
+
+// approveInstallPlan approves a manual InstallPlan only when it installs a ClusterServiceVersion on the
+// upgrade path to the pinned one, so that components do not move versions before the Workshop says so.
+// It returns whether the InstallPlan is approved.
+func (r *ReconcileWorkshop) approveInstallPlan(installPlan *olmv1alpha1.InstallPlan, pinnedCSV string, upgradePath map[string]bool) (bool, error) {
+	if installPlan.Spec.Approval != olmv1alpha1.ApprovalManual || installPlan.Spec.Approved {
+		return installPlan.Spec.Approved, nil
+	}
+	if pinnedCSV == "" || installPlan.Status.Phase != olmv1alpha1.InstallPlanPhaseRequiresApproval {
+		return false, nil
+	}
+
+	for _, csv := range installPlan.Spec.ClusterServiceVersionNames {
+		if upgradePath[csv] {
+			installPlan.Spec.Approved = true
+			if err := r.client.Update(context.TODO(), installPlan); err != nil {
+				logrus.Errorf("Failed to approve %s InstallPlan: %s", installPlan.Name, err)
+				return false, err
+			}
+			logrus.Infof("Approved %s InstallPlan for %s on the way to %s", installPlan.Name, csv, pinnedCSV)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// updateOperatorStatus records the status of an operator and only writes the status when it changed
+func (r *ReconcileWorkshop) updateOperatorStatus(instance *openshiftv1alpha1.Workshop, operatorStatus openshiftv1alpha1.OperatorStatus) error {
+	for i := range instance.Status.Operators {
+		found := instance.Status.Operators[i]
+		if found.Subscription == operatorStatus.Subscription && found.Namespace == operatorStatus.Namespace {
+			if found == operatorStatus {
+				return nil
+			}
+			instance.Status.Operators[i] = operatorStatus
+			return r.updateStatus(instance)
+		}
+	}
+
+	instance.Status.Operators = append(instance.Status.Operators, operatorStatus)
+	return r.updateStatus(instance)
+}
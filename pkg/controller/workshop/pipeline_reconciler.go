@@ -1,12 +1,9 @@
 package workshop
 
 import (
-	"context"
+	"time"
 
 	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
-	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
-	"github.com/sirupsen/logrus"
-	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -15,9 +12,7 @@ func (r *ReconcileWorkshop) reconcilePipeline(instance *openshiftv1alpha1.Worksh
 	enabledPipeline := instance.Spec.Infrastructure.Pipeline.Enabled
 
 	if enabledPipeline {
-		if result, err := r.addPipeline(instance); err != nil {
-			return result, err
-		}
+		return r.addPipeline(instance)
 	}
 
 	//Success
@@ -34,13 +29,20 @@ func (r *ReconcileWorkshop) addPipeline(instance *openshiftv1alpha1.Workshop) (r
 	// 	logrus.Infof("Created %s CatalogSourceConfig", pipelineCatalogSourceConfig.Name)
 	// }
 
-	pipelineSubscription := deployment.NewCommunitySubscription(instance, "openshift-pipelines-operator", "openshift-operators", "openshift-pipelines-operator",
-		instance.Spec.Infrastructure.Pipeline.OperatorHub.Channel,
-		instance.Spec.Infrastructure.Pipeline.OperatorHub.ClusterServiceVersion)
-	if err := r.client.Create(context.TODO(), pipelineSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	pipelineSubscription, err := r.newSubscription(instance, openshiftv1alpha1.PipelineCatalogValid, "openshift-pipelines-operator", "openshift-operators", "openshift-pipelines-operator",
+		"community-operators", instance.Spec.Infrastructure.Pipeline.OperatorHub)
+	if err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		logrus.Infof("Created %s Subscription", pipelineSubscription.Name)
+	}
+	if err := r.addUpdateSubscription(pipelineSubscription); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Wait for Pipeline Operator to be installed
+	if installed, err := r.isOperatorInstalled(instance, pipelineSubscription); err != nil {
+		return reconcile.Result{}, err
+	} else if !installed {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
 	}
 
 	//Success
@@ -0,0 +1,247 @@
+package workshop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ghodss/yaml"
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/gogs"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	deployment "github.com/redhat/openshift-workshop-operator/pkg/deployment"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+type pipelineTriggersData struct {
+	Username           string
+	ProjectName        string
+	PipelineName       string
+	GogsURL            string
+	Repositories       []string
+	AppsHostnameSuffix string
+}
+
+// Reconciling Pipeline Triggers
+func (r *ReconcileWorkshop) reconcilePipelineTriggers(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) (reconcile.Result, error) {
+	enabledPipelineTriggers := instance.Spec.Infrastructure.Pipeline.Enabled && instance.Spec.Infrastructure.Gogs.Enabled
+
+	if enabledPipelineTriggers {
+		return r.addPipelineTriggers(instance, users, appsHostnameSuffix)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *ReconcileWorkshop) addPipelineTriggers(instance *openshiftv1alpha1.Workshop, users int, appsHostnameSuffix string) (reconcile.Result, error) {
+	ctx := context.TODO()
+
+	// Wait for the repositories of the users to be seeded
+	if instance.Status.Gogs != "Available" || len(instance.Status.GogsUsers) < users {
+		logrus.Infof("Waiting for the git server to create the Pipeline Triggers")
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+	}
+
+	gogsURL, err := r.getGogsURL(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	transport, err := r.newHTTPTransport(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	adminClient, adminStatus, err := r.getGogsAdminClient(ctx, instance, gogs.New(gogsURL, transport))
+	if err != nil {
+		return reconcile.Result{}, err
+	} else if adminStatus != "" {
+		logrus.Infof("Waiting for the git server administrator to create the Pipeline Triggers: %s", adminStatus)
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Minute * 5}, nil
+	}
+
+	triggersTemplate := instance.Spec.Infrastructure.Pipeline.Triggers.Template
+	if triggersTemplate == "" {
+		triggersTemplate = deployment.PipelineTriggersTemplate
+	}
+
+	pipelineName := instance.Spec.Infrastructure.Pipeline.Triggers.PipelineName
+	if pipelineName == "" {
+		pipelineName = "ci-pipeline"
+	}
+
+	repositories := getGogsRepositories(instance)
+
+	for id := 1; id <= users; id++ {
+		username := fmt.Sprintf("user%d", id)
+		projectName := fmt.Sprintf("%s%d", instance.Spec.Infrastructure.Project.Name, id)
+
+		objects, err := renderPipelineTriggers(triggersTemplate, pipelineTriggersData{
+			Username:           username,
+			ProjectName:        projectName,
+			PipelineName:       pipelineName,
+			GogsURL:            gogsURL,
+			Repositories:       repositories,
+			AppsHostnameSuffix: appsHostnameSuffix,
+		})
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+
+		for _, object := range objects {
+			object.SetNamespace(projectName)
+			if err := r.addUpdateUnstructured(object); err != nil {
+				if meta.IsNoMatchError(err) {
+					logrus.Infof("Waiting for the Pipeline Operator to install the %s kind", object.GetKind())
+					return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
+				}
+				return reconcile.Result{}, err
+			}
+
+			if object.GetKind() != "EventListener" {
+				continue
+			}
+
+			webhookURL, err := r.addEventListenerRoute(instance, object)
+			if err != nil {
+				return reconcile.Result{}, err
+			}
+
+			for _, cloneAddr := range repositories {
+				if err := addGogsWebhook(ctx, adminClient, username, getRepositoryName(cloneAddr), webhookURL); err != nil {
+					return reconcile.Result{}, err
+				}
+			}
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// renderPipelineTriggers renders the template for a user and returns the objects it defines
+func renderPipelineTriggers(triggersTemplate string, data pipelineTriggersData) ([]*unstructured.Unstructured, error) {
+	tmpl, err := template.New("triggers").Option("missingkey=error").Parse(triggersTemplate)
+	if err != nil {
+		logrus.Errorf("Error when parsing the Pipeline Triggers template: %v", err)
+		return nil, err
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		logrus.Errorf("Error when rendering the Pipeline Triggers of %s: %v", data.Username, err)
+		return nil, err
+	}
+
+	objects := []*unstructured.Unstructured{}
+	for _, document := range strings.Split("\n"+rendered.String(), "\n---") {
+		if strings.TrimSpace(document) == "" {
+			continue
+		}
+
+		objectJSON, err := yaml.YAMLToJSON([]byte(document))
+		if err != nil {
+			logrus.Errorf("Error when converting the Pipeline Triggers of %s to JSON: %v", data.Username, err)
+			return nil, err
+		}
+
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(objectJSON); err != nil {
+			logrus.Errorf("Error when reading the Pipeline Triggers of %s: %v", data.Username, err)
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+
+	return objects, nil
+}
+
+// addUpdateUnstructured creates the object or updates its spec when it differs
+func (r *ReconcileWorkshop) addUpdateUnstructured(object *unstructured.Unstructured) error {
+	if err := r.client.Create(context.TODO(), object); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	} else if err == nil {
+		logrus.Infof("Created %s %s in %s", object.GetName(), object.GetKind(), object.GetNamespace())
+		return nil
+	}
+
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(object.GroupVersionKind())
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: object.GetName(), Namespace: object.GetNamespace()}, found); err != nil {
+		return err
+	}
+
+	if reflect.DeepEqual(found.Object["spec"], object.Object["spec"]) {
+		return nil
+	}
+
+	found.Object["spec"] = object.Object["spec"]
+	if err := r.client.Update(context.TODO(), found); err != nil {
+		return err
+	}
+	logrus.Infof("Updated %s %s in %s", found.GetName(), found.GetKind(), found.GetNamespace())
+
+	return nil
+}
+
+// addEventListenerRoute exposes the service created by Tekton Triggers for the EventListener
+// and returns the URL of the route
+func (r *ReconcileWorkshop) addEventListenerRoute(instance *openshiftv1alpha1.Workshop, eventListener *unstructured.Unstructured) (string, error) {
+	serviceName := "el-" + eventListener.GetName()
+
+	eventListenerRoute := deployment.NewRoute(instance, serviceName, eventListener.GetNamespace(), serviceName, 8080)
+	// The port of the listener depends on the version of Tekton Triggers
+	eventListenerRoute.Spec.Port = nil
+	if err := r.client.Create(context.TODO(), eventListenerRoute); err != nil && !errors.IsAlreadyExists(err) {
+		return "", err
+	} else if err == nil {
+		logrus.Infof("Created %s Route in %s", eventListenerRoute.Name, eventListenerRoute.Namespace)
+	}
+
+	return r.getRouteURL(eventListenerRoute.Name, eventListenerRoute.Namespace)
+}
+
+// addGogsWebhook registers the webhook on the repository of the user unless it already exists
+func addGogsWebhook(ctx context.Context, adminClient *gogs.Client, username string, repositoryName string, webhookURL string) error {
+	hooks, err := adminClient.ListHooks(ctx, username, repositoryName)
+	if err != nil {
+		if httpclient.IsNotFound(err) {
+			logrus.Infof("Skipped the webhook of %s/%s, the repository does not exist", username, repositoryName)
+			return nil
+		}
+		logrus.Errorf("Error when listing the webhooks of %s/%s: %v", username, repositoryName, err)
+		return err
+	}
+
+	for _, hook := range hooks {
+		if hook.Config["url"] == webhookURL {
+			return nil
+		}
+	}
+
+	if _, err := adminClient.CreateHook(ctx, username, repositoryName, &gogs.Hook{
+		Type: "gogs",
+		Config: map[string]string{
+			"url":          webhookURL,
+			"content_type": "json",
+		},
+		Events: []string{"push"},
+		Active: true,
+	}); err != nil {
+		logrus.Errorf("Error when creating the webhook of %s/%s: %v", username, repositoryName, err)
+		return err
+	}
+	logrus.Infof("Created the webhook of %s/%s to %s", username, repositoryName, webhookURL)
+
+	return nil
+}
@@ -19,9 +19,7 @@ func (r *ReconcileWorkshop) reconcileServiceMesh(instance *openshiftv1alpha1.Wor
 	enabledServiceMesh := instance.Spec.Infrastructure.ServiceMesh.Enabled
 
 	if enabledServiceMesh {
-		if result, err := r.addServiceMesh(instance, users); err != nil {
-			return result, err
-		}
+		return r.addServiceMesh(instance, users)
 	}
 
 	//Success
@@ -38,40 +36,59 @@ func (r *ReconcileWorkshop) addServiceMesh(instance *openshiftv1alpha1.Workshop,
 	// 	logrus.Infof("Created %s CatalogSourceConfig", serviceMeshCatalogSourceConfig.Name)
 	// }
 
-	// elasticSubscription := deployment.NewCertifiedSubscription(instance, "elasticsearch-operator", "openshift-operators",
-	// 	instance.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.Channel,
-	// 	instance.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.ClusterServiceVersion)
-	// if err := r.client.Create(context.TODO(), elasticSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	// elasticSubscription := deployment.NewSubscription(instance, deployment.NewSubscriptionParameters{
+	// 	Name:                   "elasticsearch-operator",
+	// 	Namespace:              "openshift-operators",
+	// 	PackageName:            "elasticsearch-operator",
+	// 	CatalogSource:          "certified-operators",
+	// 	CatalogSourceNamespace: "openshift-marketplace",
+	// 	Channel:                instance.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.Channel,
+	// 	StartingCSV:            instance.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.ClusterServiceVersion,
+	// })
+	// if err := r.addUpdateSubscription(elasticSubscription); err != nil {
 	// 	return reconcile.Result{}, err
-	// } else if err == nil {
-	// 	logrus.Infof("Created %s Subscription", elasticSubscription.Name)
 	// }
 
-	// jaegerSubscription := deployment.NewCommunitySubscription(instance, "jaeger-workshop", "openshift-operators", "jaeger",
-	// 	instance.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.Channel,
-	// 	instance.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.ClusterServiceVersion)
-	// if err := r.client.Create(context.TODO(), jaegerSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	// jaegerSubscription := deployment.NewSubscription(instance, deployment.NewSubscriptionParameters{
+	// 	Name:                   "jaeger-workshop",
+	// 	Namespace:              "openshift-operators",
+	// 	PackageName:            "jaeger",
+	// 	CatalogSource:          "community-operators",
+	// 	CatalogSourceNamespace: "openshift-marketplace",
+	// 	Channel:                instance.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.Channel,
+	// 	StartingCSV:            instance.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.ClusterServiceVersion,
+	// })
+	// if err := r.addUpdateSubscription(jaegerSubscription); err != nil {
 	// 	return reconcile.Result{}, err
-	// } else if err == nil {
-	// 	logrus.Infof("Created %s Subscription", jaegerSubscription.Name)
 	// }
 
-	// kialiSubscription := deployment.NewCommunitySubscription(instance, "kiali-workshop", "openshift-operators", "kiali",
-	// 	instance.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.Channel,
-	// 	instance.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.ClusterServiceVersion)
-	// if err := r.client.Create(context.TODO(), kialiSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	// kialiSubscription := deployment.NewSubscription(instance, deployment.NewSubscriptionParameters{
+	// 	Name:                   "kiali-workshop",
+	// 	Namespace:              "openshift-operators",
+	// 	PackageName:            "kiali",
+	// 	CatalogSource:          "community-operators",
+	// 	CatalogSourceNamespace: "openshift-marketplace",
+	// 	Channel:                instance.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.Channel,
+	// 	StartingCSV:            instance.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.ClusterServiceVersion,
+	// })
+	// if err := r.addUpdateSubscription(kialiSubscription); err != nil {
 	// 	return reconcile.Result{}, err
-	// } else if err == nil {
-	// 	logrus.Infof("Created %s Subscription", kialiSubscription.Name)
 	// }
 
-	servicemeshSubscription := deployment.NewRedHatSubscription(instance, "servicemeshoperator", "openshift-operators", "servicemeshoperator",
-		instance.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub.Channel,
-		instance.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub.ClusterServiceVersion)
-	if err := r.client.Create(context.TODO(), servicemeshSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	servicemeshSubscription, err := r.newSubscription(instance, openshiftv1alpha1.ServiceMeshCatalogValid, "servicemeshoperator", "openshift-operators", "servicemeshoperator",
+		"redhat-operators", instance.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub)
+	if err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		logrus.Infof("Created %s Subscription", servicemeshSubscription.Name)
+	}
+	if err := r.addUpdateSubscription(servicemeshSubscription); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Wait for Service Mesh Operator to be installed
+	if installed, err := r.isOperatorInstalled(instance, servicemeshSubscription); err != nil {
+		return reconcile.Result{}, err
+	} else if !installed {
+		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 30}, nil
 	}
 
 	// ISTIO-SYSTEM
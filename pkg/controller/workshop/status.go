@@ -0,0 +1,66 @@
+package workshop
+
+import (
+	"context"
+
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getCondition returns the condition of the given type or nil if it has not been reported yet
+func getCondition(instance *openshiftv1alpha1.Workshop, conditionType openshiftv1alpha1.WorkshopConditionType) *openshiftv1alpha1.WorkshopCondition {
+	for i := range instance.Status.Conditions {
+		if instance.Status.Conditions[i].Type == conditionType {
+			return &instance.Status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// setCondition adds or updates a condition and returns whether it changed.
+// LastTransitionTime only moves when the status changes.
+func setCondition(instance *openshiftv1alpha1.Workshop, conditionType openshiftv1alpha1.WorkshopConditionType,
+	status corev1.ConditionStatus, reason string, message string) bool {
+
+	condition := getCondition(instance, conditionType)
+	if condition == nil {
+		instance.Status.Conditions = append(instance.Status.Conditions, openshiftv1alpha1.WorkshopCondition{
+			Type:               conditionType,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: metav1.Now(),
+		})
+		return true
+	}
+
+	if condition.Status == status && condition.Reason == reason && condition.Message == message {
+		return false
+	}
+	if condition.Status != status {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.Message = message
+	return true
+}
+
+// updateCondition sets a condition and only writes the status when it changed
+func (r *ReconcileWorkshop) updateCondition(instance *openshiftv1alpha1.Workshop, conditionType openshiftv1alpha1.WorkshopConditionType,
+	status corev1.ConditionStatus, reason string, message string) error {
+	if !setCondition(instance, conditionType, status, reason, message) {
+		return nil
+	}
+	return r.updateStatus(instance)
+}
+
+func (r *ReconcileWorkshop) updateStatus(instance *openshiftv1alpha1.Workshop) error {
+	if err := r.client.Status().Update(context.TODO(), instance); err != nil {
+		logrus.Errorf("Failed to update %s Workshop status: %s", instance.Name, err)
+		return err
+	}
+	return nil
+}
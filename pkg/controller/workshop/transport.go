@@ -0,0 +1,194 @@
+package workshop
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	routev1 "github.com/openshift/api/route/v1"
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	"github.com/redhat/openshift-workshop-operator/pkg/client/httpclient"
+	"github.com/redhat/openshift-workshop-operator/pkg/deployment/proxy"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const serviceCAFile = "/var/run/secrets/kubernetes.io/serviceaccount/service-ca.crt"
+
+// newHTTPTransport returns the transport shared by the clients of the workshop components.
+// It trusts the service CA, the default router CA, the CAs of the cluster-wide proxy
+// and of the Workshop, and goes through the cluster-wide proxy.
+// The transport is only built again when the CAs or the proxy change, so that connections are reused.
+func (r *ReconcileWorkshop) newHTTPTransport(instance *openshiftv1alpha1.Workshop) (http.RoundTripper, error) {
+	caBundles := [][]byte{}
+
+	if serviceCA, err := ioutil.ReadFile(serviceCAFile); err == nil {
+		caBundles = append(caBundles, serviceCA)
+	} else if !os.IsNotExist(err) {
+		logrus.Warnf("Failed to read the service CA: %s", err)
+	}
+
+	// Default router CA, published for the default ingress certificate whether it is generated or custom
+	if routerCA, err := r.getConfigMapKey("default-ingress-cert", "openshift-config-managed", "ca-bundle.crt"); err != nil {
+		return nil, err
+	} else if routerCA != nil {
+		caBundles = append(caBundles, routerCA)
+	} else if routerCA, err := r.getSecretKey("router-ca", "openshift-ingress-operator", "tls.crt"); err != nil {
+		return nil, err
+	} else if routerCA != nil {
+		caBundles = append(caBundles, routerCA)
+	}
+
+	clusterProxy, err := r.getClusterProxy()
+	if err != nil {
+		return nil, err
+	}
+
+	if clusterProxy != nil && clusterProxy.Spec.TrustedCA.Name != "" {
+		proxyCA, err := r.getConfigMapKey(clusterProxy.Spec.TrustedCA.Name, "openshift-config", "ca-bundle.crt")
+		if err != nil {
+			return nil, err
+		}
+		if proxyCA == nil {
+			logrus.Warnf("Proxy trusted CA ConfigMap %s has no ca-bundle.crt key", clusterProxy.Spec.TrustedCA.Name)
+		} else {
+			caBundles = append(caBundles, proxyCA)
+		}
+	}
+
+	if trustedCA := instance.Spec.TrustedCA; trustedCA.ConfigMapName != "" {
+		key := trustedCA.Key
+		if key == "" {
+			key = "ca-bundle.crt"
+		}
+		userCA, err := r.getConfigMapKey(trustedCA.ConfigMapName, instance.Namespace, key)
+		if err != nil {
+			return nil, err
+		}
+		if userCA == nil {
+			logrus.Warnf("Trusted CA ConfigMap %s has no %s key", trustedCA.ConfigMapName, key)
+		} else {
+			caBundles = append(caBundles, userCA)
+		}
+	}
+
+	httpProxy, httpsProxy, noProxy := "", "", ""
+	if clusterProxy != nil {
+		httpProxy, httpsProxy, noProxy = clusterProxy.Status.HTTPProxy, clusterProxy.Status.HTTPSProxy, clusterProxy.Status.NoProxy
+	}
+
+	key := sha256.New()
+	for _, caBundle := range caBundles {
+		key.Write(caBundle)
+		key.Write([]byte{0})
+	}
+	key.Write([]byte(httpProxy + "\x00" + httpsProxy + "\x00" + noProxy))
+	transportKey := fmt.Sprintf("%x", key.Sum(nil))
+
+	r.transportLock.Lock()
+	defer r.transportLock.Unlock()
+	if r.transport != nil && r.transportKey == transportKey {
+		return r.transport, nil
+	}
+
+	if clusterProxy == nil {
+		r.transport = httpclient.NewTransport(caBundles, nil)
+	} else {
+		r.transport = httpclient.NewTransport(caBundles, httpclient.ProxyFunc(httpProxy, httpsProxy, noProxy))
+	}
+	r.transportKey = transportKey
+	return r.transport, nil
+}
+
+// getClusterProxy returns the cluster-wide proxy configuration, or nil when the cluster has none
+func (r *ReconcileWorkshop) getClusterProxy() (*proxy.Proxy, error) {
+	clusterProxy := &proxy.Proxy{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, clusterProxy); err != nil {
+		if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil, nil
+		}
+		logrus.Errorf("Failed to get the cluster proxy: %s", err)
+		return nil, err
+	}
+	return clusterProxy, nil
+}
+
+// getConfigMapKey returns the value of the key or nil if the ConfigMap or the key do not exist
+func (r *ReconcileWorkshop) getConfigMapKey(name string, namespace string, key string) ([]byte, error) {
+	configMap := &corev1.ConfigMap{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, configMap); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		logrus.Errorf("Failed to get %s config map: %s", name, err)
+		return nil, err
+	}
+	if value, ok := configMap.Data[key]; ok {
+		return []byte(value), nil
+	}
+	return nil, nil
+}
+
+// getSecretKey returns the value of the key or nil if the Secret or the key do not exist
+func (r *ReconcileWorkshop) getSecretKey(name string, namespace string, key string) ([]byte, error) {
+	secret := &corev1.Secret{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		logrus.Errorf("Failed to get %s secret: %s", name, err)
+		return nil, err
+	}
+	return secret.Data[key], nil
+}
+
+// getRouteURL returns the URL of the route, using HTTPS when TLS is terminated by the router,
+// or an empty string when the route does not exist
+func (r *ReconcileWorkshop) getRouteURL(name string, namespace string) (string, error) {
+	route := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, route); err != nil {
+		if errors.IsNotFound(err) {
+			return "", nil
+		}
+		logrus.Errorf("Failed to get %s route: %s", name, err)
+		return "", err
+	}
+
+	if route.Spec.TLS != nil {
+		return "https://" + route.Spec.Host, nil
+	}
+	return "http://" + route.Spec.Host, nil
+}
+
+// getAdmittedRouteURL returns the URL of the route, or an empty string when it does not exist,
+// and whether the router admitted it
+func (r *ReconcileWorkshop) getAdmittedRouteURL(name string, namespace string) (string, bool, error) {
+	route := &routev1.Route{}
+	if err := r.client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, route); err != nil {
+		if errors.IsNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+
+	routeURL := "http://" + route.Spec.Host
+	if route.Spec.TLS != nil {
+		routeURL = "https://" + route.Spec.Host
+	}
+
+	for _, ingress := range route.Status.Ingress {
+		for _, condition := range ingress.Conditions {
+			if condition.Type == routev1.RouteAdmitted && condition.Status == corev1.ConditionTrue {
+				return routeURL, true, nil
+			}
+		}
+	}
+
+	return routeURL, false, nil
+}
@@ -2,7 +2,9 @@ package workshop
 
 import (
 	"context"
+	"net/http"
 	"regexp"
+	"sync"
 
 	che "github.com/eclipse/che-operator/pkg/apis/org/v1"
 	imagev1 "github.com/openshift/api/image/v1"
@@ -16,6 +18,9 @@ import (
 	smcp "github.com/redhat/openshift-workshop-operator/pkg/deployment/maistra/servicemeshcontrolplane"
 	smmr "github.com/redhat/openshift-workshop-operator/pkg/deployment/maistra/servicemeshmemberroll"
 	nexus "github.com/redhat/openshift-workshop-operator/pkg/deployment/nexus"
+	"github.com/redhat/openshift-workshop-operator/pkg/deployment/openshiftuser"
+	"github.com/redhat/openshift-workshop-operator/pkg/deployment/packagemanifest"
+	"github.com/redhat/openshift-workshop-operator/pkg/deployment/proxy"
 	"github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -74,6 +79,21 @@ func add(mgr manager.Manager, r reconcile.Reconciler) error {
 		return err
 	}
 
+	// register PackageManifest in the scheme
+	if err := packagemanifest.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+
+	// register the cluster-wide Proxy configuration in the scheme
+	if err := proxy.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+
+	// register OpenShift Users in the scheme
+	if err := openshiftuser.AddToScheme(mgr.GetScheme()); err != nil {
+		return err
+	}
+
 	// register OpenShift Routes in the scheme
 	if err := routev1.AddToScheme(mgr.GetScheme()); err != nil {
 		return err
@@ -225,6 +245,15 @@ type ReconcileWorkshop struct {
 	// that reads objects from the cache and writes to the apiserver
 	client client.Client
 	scheme *runtime.Scheme
+
+	// HTTP transport reused across reconciles while the CAs and the proxy do not change
+	transportLock sync.Mutex
+	transport     http.RoundTripper
+	transportKey  string
+
+	// ClusterServiceVersions resolved for the Subscriptions, by namespace/name
+	resolvedCSVsLock sync.Mutex
+	resolvedCSVs     map[string]resolvedCSV
 }
 
 // Reconcile reads that state of the cluster for a Workshop object and makes changes based on the state read
@@ -281,6 +310,16 @@ func (r *ReconcileWorkshop) Reconcile(request reconcile.Request) (reconcile.Resu
 		users = 0
 	}
 
+	// Components waiting on OLM ask to be requeued without blocking the others
+	requeueResult := reconcile.Result{}
+
+	//////////////////////////
+	// Capacity
+	//////////////////////////
+	if err := r.reconcileCapacity(instance, users); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	//////////////////////////
 	// Projects
 	//////////////////////////
@@ -299,15 +338,19 @@ func (r *ReconcileWorkshop) Reconcile(request reconcile.Request) (reconcile.Resu
 	//////////////////////////
 	// Etherpad
 	//////////////////////////
-	if err := r.reconcileEtherpad(instance, users, appsHostnameSuffix); err != nil {
-		return reconcile.Result{}, err
+	if result, err := r.reconcileEtherpad(instance, users, appsHostnameSuffix); err != nil {
+		return result, err
+	} else if result.Requeue {
+		requeueResult = result
 	}
 
 	//////////////////////////
 	// Nexus
 	//////////////////////////
-	if err := r.reconcileNexus(instance); err != nil {
-		return reconcile.Result{}, err
+	if result, err := r.reconcileNexus(instance, users); err != nil {
+		return result, err
+	} else if result.Requeue {
+		requeueResult = result
 	}
 
 	//////////////////////////
@@ -315,13 +358,26 @@ func (r *ReconcileWorkshop) Reconcile(request reconcile.Request) (reconcile.Resu
 	//////////////////////////
 	if result, err := r.reconcilePipeline(instance); err != nil {
 		return result, err
+	} else if result.Requeue {
+		requeueResult = result
 	}
 
 	//////////////////////////
 	// Gogs
 	//////////////////////////
-	if result, err := r.reconcileGogs(instance); err != nil {
+	if result, err := r.reconcileGogs(instance, users, appsHostnameSuffix); err != nil {
 		return result, err
+	} else if result.Requeue {
+		requeueResult = result
+	}
+
+	//////////////////////////
+	// Pipeline Triggers
+	//////////////////////////
+	if result, err := r.reconcilePipelineTriggers(instance, users, appsHostnameSuffix); err != nil {
+		return result, err
+	} else if result.Requeue {
+		requeueResult = result
 	}
 
 	//////////////////////////
@@ -330,6 +386,17 @@ func (r *ReconcileWorkshop) Reconcile(request reconcile.Request) (reconcile.Resu
 	if result, err := r.reconcileChe(instance, users, appsHostnameSuffix,
 		openshiftConsoleURL, openshiftAPIURL); err != nil {
 		return result, err
+	} else if result.Requeue {
+		requeueResult = result
+	}
+
+	//////////////////////////
+	// Image Puller
+	//////////////////////////
+	if result, err := r.reconcileImagePuller(instance, appsHostnameSuffix); err != nil {
+		return result, err
+	} else if result.Requeue {
+		requeueResult = result
 	}
 
 	//////////////////////////
@@ -344,8 +411,10 @@ func (r *ReconcileWorkshop) Reconcile(request reconcile.Request) (reconcile.Resu
 	//////////////////////////
 	if result, err := r.reconcileServiceMesh(instance, users); err != nil {
 		return result, err
+	} else if result.Requeue {
+		requeueResult = result
 	}
 
 	//Success
-	return reconcile.Result{}, nil
+	return requeueResult, nil
 }
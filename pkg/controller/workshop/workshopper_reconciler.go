@@ -21,6 +21,11 @@ func (r *ReconcileWorkshop) reconcileWorkshopper(instance *openshiftv1alpha1.Wor
 	appsHostnameSuffix string, openshiftConsoleURL string, openshiftAPIURL string) (reconcile.Result, error) {
 	enabledWorkshopper := instance.Spec.Infrastructure.Workshopper.Enabled
 
+	gogsURL, err := r.getGogsURL(instance)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
 	id := 1
 	for {
 		username := fmt.Sprintf("user%d", id)
@@ -30,7 +35,7 @@ func (r *ReconcileWorkshop) reconcileWorkshopper(instance *openshiftv1alpha1.Wor
 		if id <= users && enabledWorkshopper {
 			// Guide
 			if result, err := r.addUpdateWorkshopper(instance, projectName, infraProjectName, username,
-				appsHostnameSuffix, openshiftConsoleURL, openshiftAPIURL); err != nil {
+				appsHostnameSuffix, openshiftConsoleURL, openshiftAPIURL, gogsURL); err != nil {
 				return result, err
 			}
 		} else {
@@ -56,7 +61,7 @@ func (r *ReconcileWorkshop) reconcileWorkshopper(instance *openshiftv1alpha1.Wor
 }
 
 func (r *ReconcileWorkshop) addUpdateWorkshopper(instance *openshiftv1alpha1.Workshop, projectName string, infraProjectName string, username string,
-	appsHostnameSuffix string, openshiftConsoleURL string, openshiftAPIURL string) (reconcile.Result, error) {
+	appsHostnameSuffix string, openshiftConsoleURL string, openshiftAPIURL string, gogsURL string) (reconcile.Result, error) {
 
 	workshopperNamespace := deployment.NewNamespace(instance, infraProjectName)
 	if err := r.client.Create(context.TODO(), workshopperNamespace); err != nil && !errors.IsAlreadyExists(err) {
@@ -65,9 +70,10 @@ func (r *ReconcileWorkshop) addUpdateWorkshopper(instance *openshiftv1alpha1.Wor
 		logrus.Infof("Created %s Namespace", workshopperNamespace.Name)
 	}
 
-	// Deploy/Update Guide
+	// Deploy/Update Guide, the route of Nexus is shown to the attendees while builds use the service like the workspaces
 	guideDeployment := deployment.NewWorkshopperDeployment(instance, "guide", infraProjectName, projectName,
-		infraProjectName, username, appsHostnameSuffix, openshiftConsoleURL, openshiftAPIURL)
+		infraProjectName, username, appsHostnameSuffix, openshiftConsoleURL, openshiftAPIURL, gogsURL, instance.Status.NexusURL,
+		getNexusServiceRepositoryURL(instance, "maven2"))
 	if err := r.client.Create(context.TODO(), guideDeployment); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
@@ -6,6 +6,16 @@ import (
 )
 
 func NewGogsCustomResource(cr *openshiftv1alpha1.Workshop, name string, namespace string) *Gogs {
+	gogsVolumeSize := cr.Spec.Infrastructure.Gogs.GogsVolumeSize
+	if gogsVolumeSize == "" {
+		gogsVolumeSize = "4Gi"
+	}
+
+	postgresqlVolumeSize := cr.Spec.Infrastructure.Gogs.PostgresqlVolumeSize
+	if postgresqlVolumeSize == "" {
+		postgresqlVolumeSize = "4Gi"
+	}
+
 	return &Gogs{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Gogs",
@@ -16,9 +26,27 @@ func NewGogsCustomResource(cr *openshiftv1alpha1.Workshop, name string, namespac
 			Namespace: namespace,
 		},
 		Spec: GogsSpec{
-			GogsVolumeSize:       "4Gi",
-			GogsSsl:              false,
-			PostgresqlVolumeSize: "4Gi",
+			GogsVolumeSize:       gogsVolumeSize,
+			GogsSsl:              cr.Spec.Infrastructure.Gogs.Ssl,
+			GogsServiceName:      GetServiceName(cr, name),
+			PostgresqlVolumeSize: postgresqlVolumeSize,
 		},
 	}
 }
+
+// GetServiceName returns the name of the service and route created by the Gogs Operator for the custom resource
+func GetServiceName(cr *openshiftv1alpha1.Workshop, name string) string {
+	if cr.Spec.Infrastructure.Gogs.ServiceName != "" {
+		return cr.Spec.Infrastructure.Gogs.ServiceName
+	}
+	return "gogs-" + name
+}
+
+// UpdateCustomResource copies the desired spec into found and returns whether it changed
+func UpdateCustomResource(found *Gogs, desired *Gogs) bool {
+	if found.Spec == desired.Spec {
+		return false
+	}
+	found.Spec = desired.Spec
+	return true
+}
@@ -6,10 +6,8 @@ import "k8s.io/apimachinery/pkg/runtime"
 // same type that is provided as a pointer.
 func (in *Gogs) DeepCopyInto(out *Gogs) {
 	out.TypeMeta = in.TypeMeta
-	out.ObjectMeta = in.ObjectMeta
-	out.Spec = GogsSpec{
-		GogsVolumeSize: in.Spec.GogsVolumeSize,
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
 }
 
 // DeepCopyObject returns a generically typed copy of an object
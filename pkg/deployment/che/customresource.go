@@ -7,6 +7,23 @@ import (
 )
 
 func NewCustomResource(cr *openshiftv1alpha1.Workshop, name string, namespace string) *che.CheCluster {
+	cheSpec := cr.Spec.Infrastructure.Che
+
+	pluginRegistryImage := cheSpec.PluginRegistryImage
+	if pluginRegistryImage == "" {
+		pluginRegistryImage = "quay.io/mcouliba/che-plugin-registry:7.3.x"
+	}
+
+	pvcStrategy := cheSpec.Storage.PvcStrategy
+	if pvcStrategy == "" {
+		pvcStrategy = "per-workspace"
+	}
+
+	pvcClaimSize := cheSpec.Storage.PvcClaimSize
+	if pvcClaimSize == "" {
+		pvcClaimSize = "1Gi"
+	}
+
 	return &che.CheCluster{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "CheCluster",
@@ -18,11 +35,12 @@ func NewCustomResource(cr *openshiftv1alpha1.Workshop, name string, namespace st
 		},
 		Spec: che.CheClusterSpec{
 			Server: che.CheClusterSpecServer{
-				CheImageTag:          "",
-				DevfileRegistryImage: "",
-				PluginRegistryImage:  "quay.io/mcouliba/che-plugin-registry:7.3.x",
-				TlsSupport:           false,
-				SelfSignedCert:       false,
+				CheImage:             cheSpec.ServerImage.Name,
+				CheImageTag:          cheSpec.ServerImage.Tag,
+				DevfileRegistryImage: cheSpec.DevfileRegistryImage,
+				PluginRegistryImage:  pluginRegistryImage,
+				TlsSupport:           cheSpec.TLSSupport,
+				SelfSignedCert:       cheSpec.SelfSignedCert,
 			},
 			Database: che.CheClusterSpecDB{
 				ExternalDB:            false,
@@ -39,14 +57,47 @@ func NewCustomResource(cr *openshiftv1alpha1.Workshop, name string, namespace st
 				KeycloakURL:           "",
 				KeycloakRealm:         "",
 				KeycloakClientId:      "",
-				KeycloakAdminUserName: "admin",
-				KeycloakAdminPassword: "admin",
+				KeycloakAdminUserName: "",
+				KeycloakAdminPassword: "",
 			},
 			Storage: che.CheClusterSpecStorage{
-				PvcStrategy:       "per-workspace",
-				PvcClaimSize:      "1Gi",
-				PreCreateSubPaths: true,
+				PvcStrategy:                  pvcStrategy,
+				PvcClaimSize:                 pvcClaimSize,
+				PreCreateSubPaths:            true,
+				WorkspacePVCStorageClassName: cheSpec.Storage.StorageClassName,
 			},
 		},
 	}
 }
+
+// UpdateCustomResource copies the settings managed by the Workshop into an existing CheCluster,
+// leaving the fields generated by the Che Operator untouched, and returns whether anything changed
+func UpdateCustomResource(found *che.CheCluster, desired *che.CheCluster) bool {
+	changed := false
+
+	if found.Spec.Server.CheImage != desired.Spec.Server.CheImage ||
+		found.Spec.Server.CheImageTag != desired.Spec.Server.CheImageTag ||
+		found.Spec.Server.DevfileRegistryImage != desired.Spec.Server.DevfileRegistryImage ||
+		found.Spec.Server.PluginRegistryImage != desired.Spec.Server.PluginRegistryImage ||
+		found.Spec.Server.TlsSupport != desired.Spec.Server.TlsSupport ||
+		found.Spec.Server.SelfSignedCert != desired.Spec.Server.SelfSignedCert {
+		found.Spec.Server.CheImage = desired.Spec.Server.CheImage
+		found.Spec.Server.CheImageTag = desired.Spec.Server.CheImageTag
+		found.Spec.Server.DevfileRegistryImage = desired.Spec.Server.DevfileRegistryImage
+		found.Spec.Server.PluginRegistryImage = desired.Spec.Server.PluginRegistryImage
+		found.Spec.Server.TlsSupport = desired.Spec.Server.TlsSupport
+		found.Spec.Server.SelfSignedCert = desired.Spec.Server.SelfSignedCert
+		changed = true
+	}
+
+	if found.Spec.Storage.PvcStrategy != desired.Spec.Storage.PvcStrategy ||
+		found.Spec.Storage.PvcClaimSize != desired.Spec.Storage.PvcClaimSize ||
+		found.Spec.Storage.WorkspacePVCStorageClassName != desired.Spec.Storage.WorkspacePVCStorageClassName {
+		found.Spec.Storage.PvcStrategy = desired.Spec.Storage.PvcStrategy
+		found.Spec.Storage.PvcClaimSize = desired.Spec.Storage.PvcClaimSize
+		found.Spec.Storage.WorkspacePVCStorageClassName = desired.Spec.Storage.WorkspacePVCStorageClassName
+		changed = true
+	}
+
+	return changed
+}
@@ -1,9 +1,12 @@
 package deployment
 
 import (
+	"bytes"
 	"encoding/json"
-	"strings"
+	"fmt"
+	"text/template"
 
+	"github.com/ghodss/yaml"
 	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -113,9 +116,8 @@ type (
 	}
 )
 
-func NewDefaultPadText(userEndpointStr string) string {
-
-	defaultPadText := `Welcome to the Cloud Native Workshop.
+// EtherpadPadTemplate is the default Go template of the welcome pad
+const EtherpadPadTemplate = `Welcome to the {{.WorkshopName}} workshop.
 
 If this is your first time using an Etherpad, it allows EVERYONE to edit
 documents collaboratively in real-time, much like a live multi-player editor
@@ -127,19 +129,68 @@ To start using this collaboration tool, we are going to identify some logistics.
 Find an open user login and assign yourself one. Remember it, you will use it
 to login:
 
-<USER_ENDPOINTS>
-
+{{range .Users}}You are {{.Username}}	|	{{if .GuideURL}}{{.GuideURL}}	|	{{end}}<INSERT_YOUR_NAME>
+{{end}}
 Parking Lot
 -----------
 If there is anything we do not have time to cover, record it here.`
 
-	return strings.Replace(defaultPadText, "<USER_ENDPOINTS>", userEndpointStr, 1)
+// EtherpadPadData is passed to the template of the welcome pad
+type EtherpadPadData struct {
+	WorkshopName       string
+	Users              []EtherpadPadUser
+	GogsURL            string
+	NexusURL           string
+	CheURL             string
+	AppsHostnameSuffix string
+}
+
+type EtherpadPadUser struct {
+	Username    string
+	ProjectName string
+	// Empty when the guide is not deployed
+	GuideURL string
+}
+
+// RenderEtherpadPad renders the template of the welcome pad, the default one when empty
+func RenderEtherpadPad(padTemplate string, data EtherpadPadData) (string, error) {
+	if padTemplate == "" {
+		padTemplate = EtherpadPadTemplate
+	}
+
+	tmpl, err := template.New("pad").Option("missingkey=error").Parse(padTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the Etherpad pad template: %s", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed to render the Etherpad pad template: %s", err)
+	}
+
+	return rendered.String(), nil
 }
 
-func NewEtherpadSettingsJson(cr *openshiftv1alpha1.Workshop, userEndpointStr string) string {
+// EtherpadDefaultPadText is the text of the pads created by the attendees. It does not depend on the users
+// or the URLs of the workshop, which are written to the welcome pad through the API, so that Etherpad
+// is only restarted when the title, the language or the settings change.
+const EtherpadDefaultPadText = `Welcome to the workshop Etherpad.
+
+This pad is edited by EVERYONE in real-time, the list of the users and their guides is in the welcome pad.`
+
+// NewEtherpadSettingsJson returns settings.json with the settings of the Workshop spec merged into the defaults
+func NewEtherpadSettingsJson(cr *openshiftv1alpha1.Workshop) (string, error) {
+	title := "OpenShift Workshop Etherpad"
+	if cr.Spec.Infrastructure.Etherpad.Title != "" {
+		title = cr.Spec.Infrastructure.Etherpad.Title
+	}
+	language := "en-gb"
+	if cr.Spec.Infrastructure.Etherpad.Language != "" {
+		language = cr.Spec.Infrastructure.Etherpad.Language
+	}
 
 	settings := &EtherpadSettings{
-		Title:   "OpenShift Workshop Etherpad",
+		Title:   title,
 		Favicon: "favicon.ico",
 		IP:      "0.0.0.0",
 		Port:    9001,
@@ -151,7 +202,7 @@ func NewEtherpadSettingsJson(cr *openshiftv1alpha1.Workshop, userEndpointStr str
 			Password: "DB_PASS",
 			Database: "DB_DBID",
 		},
-		DefaultPadText: NewDefaultPadText(userEndpointStr),
+		DefaultPadText: EtherpadDefaultPadText,
 		PadOptions: PadOptions{
 			NoColors:         false,
 			ShowControls:     true,
@@ -163,7 +214,7 @@ func NewEtherpadSettingsJson(cr *openshiftv1alpha1.Workshop, userEndpointStr str
 			Rtl:              false,
 			AlwaysShowChat:   false,
 			ChatAndUsers:     false,
-			Lang:             "en-gb",
+			Lang:             language,
 		},
 		SuppressErrorsInPadText: false,
 		RequireSession:          false,
@@ -178,7 +229,7 @@ func NewEtherpadSettingsJson(cr *openshiftv1alpha1.Workshop, userEndpointStr str
 		DisableIPlogging:        false,
 		Users: Users{
 			Admin: Admin{
-				Password: "${MYSQL_ROOT_PASSWORD}",
+				Password: "${ETHERPAD_ADMIN_PASSWORD}",
 				Is_admin: true,
 			},
 		},
@@ -194,8 +245,45 @@ func NewEtherpadSettingsJson(cr *openshiftv1alpha1.Workshop, userEndpointStr str
 		},
 	}
 
-	jsonResult, _ := json.MarshalIndent(settings, "", "  ")
-	return string(jsonResult)
+	settingsJSON, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal(settingsJSON, &merged); err != nil {
+		return "", err
+	}
+
+	if cr.Spec.Infrastructure.Etherpad.Settings != "" {
+		overlayJSON, err := yaml.YAMLToJSON([]byte(cr.Spec.Infrastructure.Etherpad.Settings))
+		if err != nil {
+			return "", fmt.Errorf("failed to read the Etherpad settings: %s", err)
+		}
+		overlay := map[string]interface{}{}
+		if err := json.Unmarshal(overlayJSON, &overlay); err != nil {
+			return "", fmt.Errorf("failed to read the Etherpad settings: %s", err)
+		}
+		mergeSettings(merged, overlay)
+	}
+
+	jsonResult, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(jsonResult), nil
+}
+
+// mergeSettings sets the values of overlay into settings, merging the nested objects
+func mergeSettings(settings map[string]interface{}, overlay map[string]interface{}) {
+	for key, value := range overlay {
+		if overlayObject, ok := value.(map[string]interface{}); ok {
+			if settingsObject, ok := settings[key].(map[string]interface{}); ok {
+				mergeSettings(settingsObject, overlayObject)
+				continue
+			}
+		}
+		settings[key] = value
+	}
 }
 
 func NewEtherpadDatabaseDeployment(cr *openshiftv1alpha1.Workshop, name string, namespace string) *appsv1.Deployment {
@@ -379,6 +467,7 @@ func NewEtherpadDeployment(cr *openshiftv1alpha1.Workshop, name string, namespac
 			Name:  "NODE_ENV",
 			Value: "production",
 		},
+		newSecretEnvVar("ETHERPAD_ADMIN_PASSWORD", name+"-admin", "password"),
 	}
 
 	return &appsv1.Deployment{
@@ -459,6 +548,12 @@ func NewEtherpadDeployment(cr *openshiftv1alpha1.Workshop, name string, namespac
 									Name:      name + "-settings",
 									MountPath: "/opt/etherpad/config",
 								},
+								{
+									// Read by Etherpad at startup to authenticate the calls to its API
+									Name:      name + "-api",
+									MountPath: "/opt/etherpad/APIKEY.txt",
+									SubPath:   "APIKEY.txt",
+								},
 							},
 							Env: env,
 						},
@@ -474,6 +569,14 @@ func NewEtherpadDeployment(cr *openshiftv1alpha1.Workshop, name string, namespac
 								},
 							},
 						},
+						{
+							Name: name + "-api",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: name + "-api",
+								},
+							},
+						},
 					},
 				},
 			},
@@ -0,0 +1,113 @@
+package deployment
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+)
+
+func TestMergeSettings(t *testing.T) {
+	settings := map[string]interface{}{
+		"title":    "OpenShift Workshop Etherpad",
+		"loglevel": "INFO",
+		"padOptions": map[string]interface{}{
+			"showChat": true,
+			"lang":     "en-gb",
+		},
+		"socketTransportProtocols": []interface{}{"xhr-polling"},
+	}
+	overlay := map[string]interface{}{
+		"loglevel": "WARN",
+		"padOptions": map[string]interface{}{
+			"showChat": false,
+		},
+		"socketTransportProtocols": []interface{}{"websocket"},
+		"skinName":                 "colibris",
+	}
+
+	mergeSettings(settings, overlay)
+
+	expected := map[string]interface{}{
+		"title":    "OpenShift Workshop Etherpad",
+		"loglevel": "WARN",
+		"padOptions": map[string]interface{}{
+			"showChat": false,
+			"lang":     "en-gb",
+		},
+		"socketTransportProtocols": []interface{}{"websocket"},
+		"skinName":                 "colibris",
+	}
+	if !reflect.DeepEqual(settings, expected) {
+		t.Errorf("expected %v, got %v", expected, settings)
+	}
+}
+
+func TestNewEtherpadSettingsJson(t *testing.T) {
+	cr := &openshiftv1alpha1.Workshop{}
+	cr.Spec.Infrastructure.Etherpad.Title = "Cloud Native Workshop"
+	cr.Spec.Infrastructure.Etherpad.Settings = "loglevel: WARN\npadOptions:\n  showChat: false\n"
+
+	settingsJSON, err := NewEtherpadSettingsJson(cr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	settings := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(settingsJSON), &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings["title"] != "Cloud Native Workshop" || settings["loglevel"] != "WARN" {
+		t.Errorf("unexpected settings %v", settings)
+	}
+	padOptions, _ := settings["padOptions"].(map[string]interface{})
+	if padOptions["showChat"] != false || padOptions["lang"] != "en-gb" {
+		t.Errorf("unexpected pad options %v", padOptions)
+	}
+	if settings["defaultPadText"] != EtherpadDefaultPadText {
+		t.Errorf("unexpected default pad text %v", settings["defaultPadText"])
+	}
+}
+
+func TestNewEtherpadSettingsJsonInvalidOverlay(t *testing.T) {
+	for _, overlay := range []string{"loglevel: [WARN", "- loglevel: WARN"} {
+		cr := &openshiftv1alpha1.Workshop{}
+		cr.Spec.Infrastructure.Etherpad.Settings = overlay
+		if _, err := NewEtherpadSettingsJson(cr); err == nil {
+			t.Errorf("expected an error for the overlay %q", overlay)
+		}
+	}
+}
+
+func TestRenderEtherpadPadDefaultTemplate(t *testing.T) {
+	padText, err := RenderEtherpadPad("", EtherpadPadData{
+		WorkshopName: "cloud-native",
+		Users: []EtherpadPadUser{
+			{Username: "user1", ProjectName: "coolstore1", GuideURL: "http://guide-infra1.apps.cluster.example.com"},
+			{Username: "user2", ProjectName: "coolstore2"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, expected := range []string{
+		"Welcome to the cloud-native workshop.",
+		"You are user1\t|\thttp://guide-infra1.apps.cluster.example.com\t|\t<INSERT_YOUR_NAME>\n",
+		"You are user2\t|\t<INSERT_YOUR_NAME>\n",
+	} {
+		if !strings.Contains(padText, expected) {
+			t.Errorf("expected %q in the pad, got:\n%s", expected, padText)
+		}
+	}
+}
+
+func TestRenderEtherpadPadInvalidTemplate(t *testing.T) {
+	for _, padTemplate := range []string{"{{range .Users}}", "{{.Unknown}}"} {
+		if _, err := RenderEtherpadPad(padTemplate, EtherpadPadData{}); err == nil {
+			t.Errorf("expected an error for the template %q", padTemplate)
+		}
+	}
+}
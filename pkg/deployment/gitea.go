@@ -0,0 +1,303 @@
+package deployment
+
+import (
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func NewGiteaDatabaseDeployment(cr *openshiftv1alpha1.Workshop, name string, namespace string) *appsv1.Deployment {
+	giteaDatabaseImage := "image-registry.openshift-image-registry.svc:5000/openshift/postgresql:10"
+	labels := GetLabels(cr, name)
+
+	env := []corev1.EnvVar{
+		newSecretEnvVar("POSTGRESQL_USER", name, "database-user"),
+		newSecretEnvVar("POSTGRESQL_PASSWORD", name, "database-password"),
+		newSecretEnvVar("POSTGRESQL_DATABASE", name, "database-name"),
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "postgresql",
+							Image:           giteaDatabaseImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "postgresql",
+									ContainerPort: 5432,
+									Protocol:      "TCP",
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler: corev1.Handler{
+									Exec: &corev1.ExecAction{
+										Command: []string{
+											"/bin/sh",
+											"-i",
+											"-c",
+											"psql -h 127.0.0.1 -U $POSTGRESQL_USER -q -d $POSTGRESQL_DATABASE -c 'SELECT 1'",
+										},
+									},
+								},
+								InitialDelaySeconds: 5,
+								FailureThreshold:    10,
+								TimeoutSeconds:      1,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      name + "-data",
+									MountPath: "/var/lib/pgsql/data",
+								},
+							},
+							Env: env,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: name + "-data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: name,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewGiteaDeployment runs the rootless Gitea image, configured with GITEA__<section>__<key> variables.
+// The installation is locked and the registration disabled, the administrator is created or its password reset
+// by an init container with the credentials of the <name>-admin Secret.
+func NewGiteaDeployment(cr *openshiftv1alpha1.Workshop, name string, namespace string, rootURL string) *appsv1.Deployment {
+	giteaImage := GetImage(cr.Spec.Infrastructure.GitServer.Gitea.Image, "docker.io/gitea/gitea:1.15.10-rootless")
+	labels := GetLabels(cr, name)
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "GITEA__server__ROOT_URL",
+			Value: rootURL,
+		},
+		{
+			Name:  "GITEA__server__HTTP_PORT",
+			Value: "3000",
+		},
+		{
+			Name:  "GITEA__server__DISABLE_SSH",
+			Value: "true",
+		},
+		{
+			Name:  "GITEA__security__INSTALL_LOCK",
+			Value: "true",
+		},
+		{
+			Name:  "GITEA__service__DISABLE_REGISTRATION",
+			Value: "true",
+		},
+		newSecretEnvVar("GITEA__security__SECRET_KEY", name, "secret-key"),
+		{
+			Name:  "GITEA__migrations__ALLOW_LOCALNETWORKS",
+			Value: "true",
+		},
+	}
+
+	if cr.Spec.Infrastructure.GitServer.Gitea.Database == "sqlite" {
+		env = append(env, []corev1.EnvVar{
+			{
+				Name:  "GITEA__database__DB_TYPE",
+				Value: "sqlite3",
+			},
+			{
+				Name:  "GITEA__database__PATH",
+				Value: "/var/lib/gitea/data/gitea.db",
+			},
+		}...)
+	} else {
+		env = append(env, []corev1.EnvVar{
+			{
+				Name:  "GITEA__database__DB_TYPE",
+				Value: "postgres",
+			},
+			{
+				Name:  "GITEA__database__HOST",
+				Value: name + "-postgresql:5432",
+			},
+			newSecretEnvVar("GITEA__database__NAME", name+"-postgresql", "database-name"),
+			newSecretEnvVar("GITEA__database__USER", name+"-postgresql", "database-user"),
+			newSecretEnvVar("GITEA__database__PASSWD", name+"-postgresql", "database-password"),
+		}...)
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      name + "-data",
+			MountPath: "/var/lib/gitea",
+		},
+		{
+			// app.ini is written at startup from the environment
+			Name:      name + "-config",
+			MountPath: "/etc/gitea",
+		},
+	}
+
+	// Same steps as the entrypoint of the image, the database is migrated before the administrator is created
+	adminScript := `/usr/local/bin/docker-setup.sh && \
+gitea migrate --config "$GITEA_APP_INI" && \
+if gitea admin user list --config "$GITEA_APP_INI" | awk 'NR > 1 { print $2 }' | grep -qx "$GITEA_ADMIN_USERNAME"; then
+  gitea admin change-password --config "$GITEA_APP_INI" --username "$GITEA_ADMIN_USERNAME" --password "$GITEA_ADMIN_PASSWORD"
+else
+  gitea admin user create --config "$GITEA_APP_INI" --admin --username "$GITEA_ADMIN_USERNAME" \
+    --password "$GITEA_ADMIN_PASSWORD" --email "$GITEA_ADMIN_USERNAME@none.com" --must-change-password=false
+fi`
+	adminEnv := append([]corev1.EnvVar{
+		newSecretEnvVar("GITEA_ADMIN_USERNAME", name+"-admin", "username"),
+		newSecretEnvVar("GITEA_ADMIN_PASSWORD", name+"-admin", "password"),
+	}, env...)
+
+	httpProbe := corev1.Handler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path: "/api/healthz",
+			Port: intstr.IntOrString{
+				Type:   intstr.Int,
+				IntVal: int32(3000),
+			},
+			Scheme: corev1.URISchemeHTTP,
+		},
+	}
+
+	return &appsv1.Deployment{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Deployment",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RecreateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{
+						{
+							Name:            name + "-admin",
+							Image:           giteaImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"/bin/sh", "-c", adminScript},
+							VolumeMounts:    volumeMounts,
+							Env:             adminEnv,
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:            name,
+							Image:           giteaImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Ports: []corev1.ContainerPort{
+								{
+									Name:          "http",
+									ContainerPort: 3000,
+									Protocol:      "TCP",
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("256Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
+							ReadinessProbe: &corev1.Probe{
+								Handler:             httpProbe,
+								InitialDelaySeconds: 5,
+								PeriodSeconds:       10,
+								FailureThreshold:    10,
+								TimeoutSeconds:      1,
+							},
+							LivenessProbe: &corev1.Probe{
+								Handler:             httpProbe,
+								InitialDelaySeconds: 60,
+								PeriodSeconds:       10,
+								FailureThreshold:    3,
+								TimeoutSeconds:      1,
+							},
+							VolumeMounts: volumeMounts,
+							Env:          env,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: name + "-data",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: name,
+								},
+							},
+						},
+						{
+							Name: name + "-config",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newSecretEnvVar(name string, secretName string, key string) corev1.EnvVar {
+	return corev1.EnvVar{
+		Name: name,
+		ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				Key: key,
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: secretName,
+				},
+			},
+		},
+	}
+}
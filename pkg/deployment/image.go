@@ -0,0 +1,17 @@
+package deployment
+
+import (
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+)
+
+// GetImage returns the reference of the image, or defaultImage when no name is set.
+// The tag is only appended when set, so that the name can carry a digest, e.g. quay.io/org/image@sha256:...
+func GetImage(image openshiftv1alpha1.ImageSpec, defaultImage string) string {
+	if image.Name == "" {
+		return defaultImage
+	}
+	if image.Tag == "" {
+		return image.Name
+	}
+	return image.Name + ":" + image.Tag
+}
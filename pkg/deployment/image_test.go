@@ -0,0 +1,38 @@
+package deployment
+
+import (
+	"testing"
+
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+)
+
+func TestGetImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    openshiftv1alpha1.ImageSpec
+		expected string
+	}{
+		{
+			name:     "default",
+			expected: "docker.io/gitea/gitea:1.15.10-rootless",
+		},
+		{
+			name:     "tag",
+			image:    openshiftv1alpha1.ImageSpec{Name: "quay.io/workshop/gitea", Tag: "1.16"},
+			expected: "quay.io/workshop/gitea:1.16",
+		},
+		{
+			name:     "digest",
+			image:    openshiftv1alpha1.ImageSpec{Name: "quay.io/workshop/gitea@sha256:0123456789abcdef"},
+			expected: "quay.io/workshop/gitea@sha256:0123456789abcdef",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if image := GetImage(test.image, "docker.io/gitea/gitea:1.15.10-rootless"); image != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, image)
+			}
+		})
+	}
+}
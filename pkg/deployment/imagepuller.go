@@ -0,0 +1,102 @@
+package deployment
+
+import (
+	"fmt"
+
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewImagePullerDaemonSet pulls the images on every schedulable node. Each image runs as an init container
+// calling a static busybox copied beforehand, so the pod becomes ready once all the images are pulled.
+func NewImagePullerDaemonSet(cr *openshiftv1alpha1.Workshop, name string, namespace string, images []string) *appsv1.DaemonSet {
+	busyboxImage := "docker.io/library/busybox:1.31"
+	labels := GetLabels(cr, name)
+
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("10m"),
+			corev1.ResourceMemory: resource.MustParse("16Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("50m"),
+			corev1.ResourceMemory: resource.MustParse("32Mi"),
+		},
+	}
+
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "puller",
+			MountPath: "/puller",
+		},
+	}
+
+	initContainers := []corev1.Container{
+		{
+			Name:            "copy-busybox",
+			Image:           busyboxImage,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"cp", "/bin/busybox", "/puller/busybox"},
+			Resources:       resources,
+			VolumeMounts:    volumeMounts,
+		},
+	}
+	for i, image := range images {
+		initContainers = append(initContainers, corev1.Container{
+			Name:            fmt.Sprintf("image-%d", i),
+			Image:           image,
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Command:         []string{"/puller/busybox", "true"},
+			Resources:       resources,
+			VolumeMounts:    volumeMounts,
+		})
+	}
+
+	var terminationGracePeriodSeconds int64
+
+	return &appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					InitContainers: initContainers,
+					Containers: []corev1.Container{
+						{
+							Name:            "sleep",
+							Image:           busyboxImage,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"sleep", "2147483647"},
+							Resources:       resources,
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "puller",
+							VolumeSource: corev1.VolumeSource{
+								EmptyDir: &corev1.EmptyDirVolumeSource{},
+							},
+						},
+					},
+					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
+				},
+			},
+		},
+	}
+}
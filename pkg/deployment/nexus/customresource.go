@@ -1,11 +1,170 @@
 package nexus
 
 import (
+	"reflect"
+
 	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func NewCustomResource(cr *openshiftv1alpha1.Workshop, name string, namespace string) *Nexus {
+	nexusSpec := cr.Spec.Infrastructure.Nexus
+	repositories := nexusSpec.Repositories
+
+	nexusImageTag := nexusSpec.NexusImageTag
+	if nexusImageTag == "" {
+		nexusImageTag = "latest"
+	}
+
+	volumeSize := nexusSpec.VolumeSize
+	if volumeSize == "" {
+		volumeSize = "5Gi"
+	}
+
+	cpuRequest := nexusSpec.CPURequest
+	if cpuRequest == 0 {
+		cpuRequest = 1
+	}
+
+	cpuLimit := nexusSpec.CPULimit
+	if cpuLimit == 0 {
+		cpuLimit = 2
+	}
+
+	memoryRequest := nexusSpec.MemoryRequest
+	if memoryRequest == "" {
+		memoryRequest = "2Gi"
+	}
+
+	memoryLimit := nexusSpec.MemoryLimit
+	if memoryLimit == "" {
+		memoryLimit = "2Gi"
+	}
+
+	mavenProxy := []NexusReposMavenProxySpec{
+		{
+			Name:         "maven-central",
+			RemoteURL:    "https://repo1.maven.org/maven2/",
+			LayoutPolicy: "permissive",
+		},
+		{
+			Name:         "redhat-ga",
+			RemoteURL:    "https://maven.repository.redhat.com/ga/",
+			LayoutPolicy: "permissive",
+		},
+		{
+			Name:         "jboss",
+			RemoteURL:    "https://repository.jboss.org/nexus/content/groups/public",
+			LayoutPolicy: "permissive",
+		},
+	}
+	if len(repositories.MavenProxy) > 0 {
+		mavenProxy = []NexusReposMavenProxySpec{}
+		for _, repository := range repositories.MavenProxy {
+			layoutPolicy := repository.LayoutPolicy
+			if layoutPolicy == "" {
+				layoutPolicy = "permissive"
+			}
+			mavenProxy = append(mavenProxy, NexusReposMavenProxySpec{
+				Name:         repository.Name,
+				RemoteURL:    repository.RemoteURL,
+				LayoutPolicy: layoutPolicy,
+			})
+		}
+	}
+
+	mavenHosted := []NexusReposMavenHostedSpec{
+		{
+			Name:          "releases",
+			VersionPolicy: "release",
+			WritePolicy:   "allow_once",
+		},
+	}
+	if len(repositories.MavenHosted) > 0 {
+		mavenHosted = []NexusReposMavenHostedSpec{}
+		for _, repository := range repositories.MavenHosted {
+			versionPolicy := repository.VersionPolicy
+			if versionPolicy == "" {
+				versionPolicy = "release"
+			}
+			writePolicy := repository.WritePolicy
+			if writePolicy == "" {
+				writePolicy = "allow_once"
+			}
+			mavenHosted = append(mavenHosted, NexusReposMavenHostedSpec{
+				Name:          repository.Name,
+				VersionPolicy: versionPolicy,
+				WritePolicy:   writePolicy,
+			})
+		}
+	}
+
+	mavenGroup := []NexusReposMavenGroupSpec{
+		{
+			Name:        "maven-all-public",
+			MemberRepos: []string{"maven-central", "redhat-ga", "jboss"},
+		},
+	}
+	if len(repositories.MavenGroup) > 0 {
+		mavenGroup = []NexusReposMavenGroupSpec{}
+		for _, repository := range repositories.MavenGroup {
+			mavenGroup = append(mavenGroup, NexusReposMavenGroupSpec{
+				Name:        repository.Name,
+				MemberRepos: repository.MemberRepos,
+			})
+		}
+	}
+
+	dockerHosted := []NexusReposDockerHostedSpec{
+		{
+			Name:      "docker",
+			HttpPort:  5000,
+			V1Enabled: true,
+		},
+	}
+	if len(repositories.DockerHosted) > 0 {
+		dockerHosted = []NexusReposDockerHostedSpec{}
+		for _, repository := range repositories.DockerHosted {
+			dockerHosted = append(dockerHosted, NexusReposDockerHostedSpec{
+				Name:      repository.Name,
+				HttpPort:  repository.HTTPPort,
+				V1Enabled: repository.V1Enabled,
+			})
+		}
+	}
+
+	npmProxy := []NexusReposNpmProxySpec{
+		{
+			Name:      "npm",
+			RemoteURL: "https://registry.npmjs.org",
+		},
+	}
+	if len(repositories.NpmProxy) > 0 {
+		npmProxy = []NexusReposNpmProxySpec{}
+		for _, repository := range repositories.NpmProxy {
+			npmProxy = append(npmProxy, NexusReposNpmProxySpec{
+				Name:      repository.Name,
+				RemoteURL: repository.RemoteURL,
+			})
+		}
+	}
+
+	npmGroup := []NexusReposNpmGroupSpec{
+		{
+			Name:        "npm-all",
+			MemberRepos: []string{"npm"},
+		},
+	}
+	if len(repositories.NpmGroup) > 0 {
+		npmGroup = []NexusReposNpmGroupSpec{}
+		for _, repository := range repositories.NpmGroup {
+			npmGroup = append(npmGroup, NexusReposNpmGroupSpec{
+				Name:        repository.Name,
+				MemberRepos: repository.MemberRepos,
+			})
+		}
+	}
+
 	return &Nexus{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Nexus",
@@ -16,62 +175,28 @@ func NewCustomResource(cr *openshiftv1alpha1.Workshop, name string, namespace st
 			Namespace: namespace,
 		},
 		Spec: NexusSpec{
-			NexusVolumeSize:    "5Gi",
-			NexusSSL:           true,
-			NexusImageTag:      "latest",
-			NexusCPURequest:    1,
-			NexusCPULimit:      2,
-			NexusMemoryRequest: "2Gi",
-			NexusMemoryLimit:   "2Gi",
-			NexusReposMavenProxy: []NexusReposMavenProxySpec{
-				{
-					Name:         "maven-central",
-					RemoteURL:    "https://repo1.maven.org/maven2/",
-					LayoutPolicy: "permissive",
-				},
-				{
-					Name:         "redhat-ga",
-					RemoteURL:    "https://maven.repository.redhat.com/ga/",
-					LayoutPolicy: "permissive",
-				},
-				{
-					Name:         "jboss",
-					RemoteURL:    "https://repository.jboss.org/nexus/content/groups/public",
-					LayoutPolicy: "permissive",
-				},
-			},
-			NexusReposMavenHosted: []NexusReposMavenHostedSpec{
-				{
-					Name:          "releases",
-					VersionPolicy: "release",
-					WritePolicy:   "allow_once",
-				},
-			},
-			NexusReposMavenGroup: []NexusReposMavenGroupSpec{
-				{
-					Name:        "maven-all-public",
-					MemberRepos: []string{"maven-central", "redhat-ga", "jboss"},
-				},
-			},
-			NexusReposDockerHosted: []NexusReposDockerHostedSpec{
-				{
-					Name:      "docker",
-					HttpPort:  5000,
-					V1Enabled: true,
-				},
-			},
-			NexusReposNpmProxy: []NexusReposNpmProxySpec{
-				{
-					Name:      "npm",
-					RemoteURL: "https://registry.npmjs.org",
-				},
-			},
-			NexusReposNpmGroup: []NexusReposNpmGroupSpec{
-				{
-					Name:        "npm-all",
-					MemberRepos: []string{"npm"},
-				},
-			},
+			NexusVolumeSize:        volumeSize,
+			NexusSSL:               true,
+			NexusImageTag:          nexusImageTag,
+			NexusCPURequest:        cpuRequest,
+			NexusCPULimit:          cpuLimit,
+			NexusMemoryRequest:     memoryRequest,
+			NexusMemoryLimit:       memoryLimit,
+			NexusReposMavenProxy:   mavenProxy,
+			NexusReposMavenHosted:  mavenHosted,
+			NexusReposMavenGroup:   mavenGroup,
+			NexusReposDockerHosted: dockerHosted,
+			NexusReposNpmProxy:     npmProxy,
+			NexusReposNpmGroup:     npmGroup,
 		},
 	}
 }
+
+// UpdateCustomResource copies the desired spec into found and returns whether it changed
+func UpdateCustomResource(found *Nexus, desired *Nexus) bool {
+	if reflect.DeepEqual(found.Spec, desired.Spec) {
+		return false
+	}
+	found.Spec = desired.Spec
+	return true
+}
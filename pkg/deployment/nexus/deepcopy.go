@@ -6,9 +6,42 @@ import "k8s.io/apimachinery/pkg/runtime"
 // same type that is provided as a pointer.
 func (in *Nexus) DeepCopyInto(out *Nexus) {
 	out.TypeMeta = in.TypeMeta
-	out.ObjectMeta = in.ObjectMeta
-	out.Spec = NexusSpec{
-		NexusVolumeSize: in.Spec.NexusVolumeSize,
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopyInto copies the spec and its repositories
+func (in *NexusSpec) DeepCopyInto(out *NexusSpec) {
+	*out = *in
+	if in.NexusReposMavenProxy != nil {
+		out.NexusReposMavenProxy = append([]NexusReposMavenProxySpec{}, in.NexusReposMavenProxy...)
+	}
+	if in.NexusReposMavenHosted != nil {
+		out.NexusReposMavenHosted = append([]NexusReposMavenHostedSpec{}, in.NexusReposMavenHosted...)
+	}
+	if in.NexusReposMavenGroup != nil {
+		out.NexusReposMavenGroup = make([]NexusReposMavenGroupSpec, len(in.NexusReposMavenGroup))
+		for i, group := range in.NexusReposMavenGroup {
+			out.NexusReposMavenGroup[i] = NexusReposMavenGroupSpec{
+				Name:        group.Name,
+				MemberRepos: append([]string(nil), group.MemberRepos...),
+			}
+		}
+	}
+	if in.NexusReposDockerHosted != nil {
+		out.NexusReposDockerHosted = append([]NexusReposDockerHostedSpec{}, in.NexusReposDockerHosted...)
+	}
+	if in.NexusReposNpmProxy != nil {
+		out.NexusReposNpmProxy = append([]NexusReposNpmProxySpec{}, in.NexusReposNpmProxy...)
+	}
+	if in.NexusReposNpmGroup != nil {
+		out.NexusReposNpmGroup = make([]NexusReposNpmGroupSpec, len(in.NexusReposNpmGroup))
+		for i, group := range in.NexusReposNpmGroup {
+			out.NexusReposNpmGroup[i] = NexusReposNpmGroupSpec{
+				Name:        group.Name,
+				MemberRepos: append([]string(nil), group.MemberRepos...),
+			}
+		}
 	}
 }
 
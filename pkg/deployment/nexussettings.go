@@ -0,0 +1,26 @@
+package deployment
+
+import "fmt"
+
+// NewMavenSettings returns a settings.xml mirroring every repository to the Maven group of Nexus
+func NewMavenSettings(mirrorURL string) string {
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<settings xmlns="http://maven.apache.org/SETTINGS/1.0.0"
+          xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+          xsi:schemaLocation="http://maven.apache.org/SETTINGS/1.0.0 https://maven.apache.org/xsd/settings-1.0.0.xsd">
+  <mirrors>
+    <mirror>
+      <id>nexus</id>
+      <name>Nexus</name>
+      <mirrorOf>*</mirrorOf>
+      <url>%s</url>
+    </mirror>
+  </mirrors>
+</settings>
+`, mirrorURL)
+}
+
+// NewNpmrc returns a .npmrc using the npm group of Nexus as registry
+func NewNpmrc(registryURL string) string {
+	return fmt.Sprintf("registry=%s\n", registryURL)
+}
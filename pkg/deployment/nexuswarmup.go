@@ -0,0 +1,148 @@
+package deployment
+
+import (
+	"strings"
+
+	openshiftv1alpha1 "github.com/redhat/openshift-workshop-operator/pkg/apis/openshift/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Writes a settings.xml mirroring every repository to the Maven group of Nexus
+const nexusMavenSettingsScript = `cat > /tmp/settings.xml <<EOF
+<settings>
+  <localRepository>/tmp/m2</localRepository>
+  <mirrors>
+    <mirror>
+      <id>nexus</id>
+      <mirrorOf>*</mirrorOf>
+      <url>${MAVEN_MIRROR_URL}</url>
+    </mirror>
+  </mirrors>
+</settings>
+EOF
+`
+
+const nexusMavenWarmupScript = nexusMavenSettingsScript + `for artifact in "$@"; do
+  mvn -B -s /tmp/settings.xml dependency:get -Dtransitive=true -Dartifact="$artifact" || exit 1
+done
+`
+
+const nexusNpmWarmupScript = `cd /tmp && for package in "$@"; do
+  npm install --no-save --registry "$NPM_REGISTRY_URL" "$package" || exit 1
+done
+`
+
+const nexusProjectWarmupScript = nexusMavenSettingsScript + `git clone --depth 1 --branch "$2" "$1" /tmp/project && cd "/tmp/project/$3" &&
+mvn -B -s /tmp/settings.xml -DskipTests package
+`
+
+// NewNexusWarmupJob resolves the artifacts listed in the warm-up spec through the Maven and npm groups,
+// one container per kind of artifact. The pod succeeds once all the containers succeed.
+// The project is cloned through the cluster-wide proxy, when set.
+func NewNexusWarmupJob(cr *openshiftv1alpha1.Workshop, name string, namespace string, mavenGroupURL string, npmGroupURL string,
+	httpProxy string, httpsProxy string, noProxy string) *batchv1.Job {
+	warmupSpec := cr.Spec.Infrastructure.Nexus.Warmup
+	mavenImage := GetImage(warmupSpec.MavenImage, "docker.io/library/maven:3.6-jdk-11")
+	npmImage := GetImage(warmupSpec.NpmImage, "docker.io/library/node:12")
+	labels := GetLabels(cr, name)
+
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+
+	// The images run with a random user, so everything is written in /tmp
+	env := []corev1.EnvVar{
+		{
+			Name:  "HOME",
+			Value: "/tmp",
+		},
+		{
+			Name:  "MAVEN_MIRROR_URL",
+			Value: mavenGroupURL,
+		},
+		{
+			Name:  "NPM_REGISTRY_URL",
+			Value: npmGroupURL,
+		},
+	}
+
+	containers := []corev1.Container{}
+	if len(warmupSpec.Maven) > 0 && mavenGroupURL != "" {
+		containers = append(containers, corev1.Container{
+			Name:      "maven",
+			Image:     mavenImage,
+			Command:   append([]string{"/bin/sh", "-c", nexusMavenWarmupScript, "--"}, warmupSpec.Maven...),
+			Env:       env,
+			Resources: resources,
+		})
+	}
+	if len(warmupSpec.Npm) > 0 && npmGroupURL != "" {
+		containers = append(containers, corev1.Container{
+			Name:      "npm",
+			Image:     npmImage,
+			Command:   append([]string{"/bin/sh", "-c", nexusNpmWarmupScript, "--"}, warmupSpec.Npm...),
+			Env:       env,
+			Resources: resources,
+		})
+	}
+	if warmupSpec.Project != nil && warmupSpec.Project.GitURL != "" && mavenGroupURL != "" {
+		gitBranch := warmupSpec.Project.GitBranch
+		if gitBranch == "" {
+			gitBranch = "master"
+		}
+		// git reads the lower case variables, other tools the upper case ones
+		projectEnv := append([]corev1.EnvVar{}, env...)
+		for _, proxyEnv := range []corev1.EnvVar{
+			{Name: "HTTP_PROXY", Value: httpProxy},
+			{Name: "HTTPS_PROXY", Value: httpsProxy},
+			{Name: "NO_PROXY", Value: noProxy},
+		} {
+			if proxyEnv.Value != "" {
+				projectEnv = append(projectEnv, proxyEnv, corev1.EnvVar{Name: strings.ToLower(proxyEnv.Name), Value: proxyEnv.Value})
+			}
+		}
+		containers = append(containers, corev1.Container{
+			Name:      "project",
+			Image:     mavenImage,
+			Command:   []string{"/bin/sh", "-c", nexusProjectWarmupScript, "--", warmupSpec.Project.GitURL, gitBranch, warmupSpec.Project.ContextDir},
+			Env:       projectEnv,
+			Resources: resources,
+		})
+	}
+
+	var backoffLimit int32 = 2
+	var activeDeadlineSeconds int64 = 3600
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					Containers:    containers,
+					RestartPolicy: corev1.RestartPolicyNever,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,43 @@
+package openshiftuser
+
+import "k8s.io/apimachinery/pkg/runtime"
+
+// DeepCopyInto copies all properties of this object into another object of the
+// same type that is provided as a pointer.
+func (in *User) DeepCopyInto(out *User) {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.FullName = in.FullName
+	if in.Identities != nil {
+		out.Identities = make([]string, len(in.Identities))
+		copy(out.Identities, in.Identities)
+	}
+	if in.Groups != nil {
+		out.Groups = make([]string, len(in.Groups))
+		copy(out.Groups, in.Groups)
+	}
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *User) DeepCopyObject() runtime.Object {
+	out := User{}
+	in.DeepCopyInto(&out)
+
+	return &out
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *UserList) DeepCopyObject() runtime.Object {
+	out := UserList{}
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+
+	if in.Items != nil {
+		out.Items = make([]User, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+
+	return &out
+}
@@ -0,0 +1,23 @@
+package openshiftuser
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+////////////
+/// TYPE ///
+////////////
+
+// User is created by OpenShift on the first login of a person, whatever the identity provider
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	FullName   string   `json:"fullName,omitempty"`
+	Identities []string `json:"identities"`
+	Groups     []string `json:"groups"`
+}
+
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []User `json:"items"`
+}
@@ -0,0 +1,46 @@
+package packagemanifest
+
+import "k8s.io/apimachinery/pkg/runtime"
+
+// DeepCopyInto copies all properties of this object into another object of the
+// same type that is provided as a pointer.
+func (in *PackageManifest) DeepCopyInto(out *PackageManifest) {
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	if in.Status.Channels != nil {
+		out.Status.Channels = make([]PackageChannel, len(in.Status.Channels))
+		for i := range in.Status.Channels {
+			out.Status.Channels[i] = in.Status.Channels[i]
+			if in.Status.Channels[i].Entries != nil {
+				out.Status.Channels[i].Entries = make([]ChannelEntry, len(in.Status.Channels[i].Entries))
+				copy(out.Status.Channels[i].Entries, in.Status.Channels[i].Entries)
+			}
+		}
+	}
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *PackageManifest) DeepCopyObject() runtime.Object {
+	out := PackageManifest{}
+	in.DeepCopyInto(&out)
+
+	return &out
+}
+
+// DeepCopyObject returns a generically typed copy of an object
+func (in *PackageManifestList) DeepCopyObject() runtime.Object {
+	out := PackageManifestList{}
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+
+	if in.Items != nil {
+		out.Items = make([]PackageManifest, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+
+	return &out
+}
@@ -0,0 +1,44 @@
+package packagemanifest
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+////////////
+/// TYPE ///
+////////////
+
+type PackageManifest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PackageManifestSpec   `json:"spec,omitempty"`
+	Status PackageManifestStatus `json:"status,omitempty"`
+}
+
+type PackageManifestSpec struct{}
+
+type PackageManifestStatus struct {
+	CatalogSource          string           `json:"catalogSource"`
+	CatalogSourceNamespace string           `json:"catalogSourceNamespace"`
+	PackageName            string           `json:"packageName"`
+	Channels               []PackageChannel `json:"channels"`
+	DefaultChannel         string           `json:"defaultChannel"`
+}
+
+type PackageChannel struct {
+	Name       string `json:"name"`
+	CurrentCSV string `json:"currentCSV"`
+	// Only reported by recent versions of the package server
+	Entries []ChannelEntry `json:"entries,omitempty"`
+}
+
+type ChannelEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type PackageManifestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []PackageManifest `json:"items"`
+}
@@ -0,0 +1,55 @@
+package deployment
+
+// PipelineTriggersTemplate starts the pipeline named .PipelineName of the project of the user
+// when one of the repositories of the user in Gogs receives a push
+const PipelineTriggersTemplate = `apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerBinding
+metadata:
+  name: gogs-push
+spec:
+  params:
+  - name: git-repo-url
+    value: $(body.repository.clone_url)
+  - name: git-revision
+    value: $(body.after)
+---
+apiVersion: triggers.tekton.dev/v1alpha1
+kind: TriggerTemplate
+metadata:
+  name: {{.PipelineName}}-run
+spec:
+  params:
+  - name: git-repo-url
+  - name: git-revision
+    default: master
+  resourcetemplates:
+  - apiVersion: tekton.dev/v1alpha1
+    kind: PipelineRun
+    metadata:
+      generateName: {{.PipelineName}}-
+    spec:
+      serviceAccountName: pipeline
+      pipelineRef:
+        name: {{.PipelineName}}
+      resources:
+      - name: app-git
+        resourceSpec:
+          type: git
+          params:
+          - name: url
+            value: $(params.git-repo-url)
+          - name: revision
+            value: $(params.git-revision)
+---
+apiVersion: triggers.tekton.dev/v1alpha1
+kind: EventListener
+metadata:
+  name: gogs-listener
+spec:
+  serviceAccountName: pipeline
+  triggers:
+  - bindings:
+    - name: gogs-push
+    template:
+      name: {{.PipelineName}}-run
+`
@@ -0,0 +1,41 @@
+package proxy
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+////////////
+/// TYPE ///
+////////////
+
+// Proxy is the cluster-wide proxy configuration, named cluster
+type Proxy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProxySpec   `json:"spec,omitempty"`
+	Status ProxyStatus `json:"status,omitempty"`
+}
+
+type ProxySpec struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	NoProxy    string `json:"noProxy,omitempty"`
+	// ConfigMap in openshift-config holding additional trusted CAs in ca-bundle.crt
+	TrustedCA ConfigMapNameReference `json:"trustedCA,omitempty"`
+}
+
+type ConfigMapNameReference struct {
+	Name string `json:"name"`
+}
+
+// ProxyStatus holds the proxy settings in effect, including the generated noProxy entries
+type ProxyStatus struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	NoProxy    string `json:"noProxy,omitempty"`
+}
+
+type ProxyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Proxy `json:"items"`
+}
@@ -7,77 +7,45 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func NewCertifiedSubscription(cr *openshiftv1alpha1.Workshop, name string, namespace string, packageName string, channel string, startingCSV string) *olmv1alpha1.Subscription {
-	return &olmv1alpha1.Subscription{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Subscription",
-			APIVersion: "operators.coreos.com/v1alpha1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"csc-owner-name":      "certified-operators",
-				"csc-owner-namespace": "openshift-marketplace",
-			},
-		},
-		Spec: &olmv1alpha1.SubscriptionSpec{
-			Channel:                channel,
-			CatalogSource:          "certified-operators",
-			CatalogSourceNamespace: "openshift-marketplace",
-			StartingCSV:            startingCSV,
-			InstallPlanApproval:    olmv1alpha1.ApprovalAutomatic,
-			Package:                packageName,
-		},
-	}
+type NewSubscriptionParameters struct {
+	Name                   string
+	Namespace              string
+	PackageName            string
+	CatalogSource          string
+	CatalogSourceNamespace string
+	Channel                string
+	StartingCSV            string
+	InstallPlanApproval    string
 }
 
-func NewCommunitySubscription(cr *openshiftv1alpha1.Workshop, name string, namespace string, packageName string, channel string, startingCSV string) *olmv1alpha1.Subscription {
+func NewSubscription(cr *openshiftv1alpha1.Workshop, param NewSubscriptionParameters) *olmv1alpha1.Subscription {
 	return &olmv1alpha1.Subscription{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Subscription",
 			APIVersion: "operators.coreos.com/v1alpha1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
+			Name:      param.Name,
+			Namespace: param.Namespace,
 			Labels: map[string]string{
-				"csc-owner-name":      "community-operators",
-				"csc-owner-namespace": "openshift-marketplace",
+				"csc-owner-name":      param.CatalogSource,
+				"csc-owner-namespace": param.CatalogSourceNamespace,
 			},
 		},
 		Spec: &olmv1alpha1.SubscriptionSpec{
-			Channel:                channel,
-			CatalogSource:          "community-operators",
-			CatalogSourceNamespace: "openshift-marketplace",
-			StartingCSV:            startingCSV,
-			InstallPlanApproval:    olmv1alpha1.ApprovalAutomatic,
-			Package:                packageName,
+			Channel:                param.Channel,
+			CatalogSource:          param.CatalogSource,
+			CatalogSourceNamespace: param.CatalogSourceNamespace,
+			StartingCSV:            param.StartingCSV,
+			InstallPlanApproval:    newInstallPlanApproval(param.InstallPlanApproval),
+			Package:                param.PackageName,
 		},
 	}
 }
 
-func NewRedHatSubscription(cr *openshiftv1alpha1.Workshop, name string, namespace string, packageName string, channel string, startingCSV string) *olmv1alpha1.Subscription {
-	return &olmv1alpha1.Subscription{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "Subscription",
-			APIVersion: "operators.coreos.com/v1alpha1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"csc-owner-name":      "redhat-operators",
-				"csc-owner-namespace": "openshift-marketplace",
-			},
-		},
-		Spec: &olmv1alpha1.SubscriptionSpec{
-			Channel:                channel,
-			CatalogSource:          "redhat-operators",
-			CatalogSourceNamespace: "openshift-marketplace",
-			StartingCSV:            startingCSV,
-			InstallPlanApproval:    olmv1alpha1.ApprovalAutomatic,
-			Package:                packageName,
-		},
+func newInstallPlanApproval(approval string) olmv1alpha1.Approval {
+	if approval == string(olmv1alpha1.ApprovalManual) {
+		return olmv1alpha1.ApprovalManual
 	}
+	return olmv1alpha1.ApprovalAutomatic
 }
@@ -13,7 +13,7 @@ import (
 
 func NewWorkshopperDeployment(cr *openshiftv1alpha1.Workshop, name string, namespace string,
 	projectName string, infraProjectName string, username string, appsHostnameSuffix string,
-	openshiftConsoleURL string, openshiftAPIURL string) *appsv1.Deployment {
+	openshiftConsoleURL string, openshiftAPIURL string, gogsURL string, nexusURL string, mavenMirrorURL string) *appsv1.Deployment {
 	workshopperImage := "quay.io/osevg/workshopper:latest"
 	labels := GetLabels(cr, name)
 
@@ -75,11 +75,15 @@ func NewWorkshopperDeployment(cr *openshiftv1alpha1.Workshop, name string, names
 		},
 		{
 			Name:  "GOGS_URL",
-			Value: "http://gogs-gogs-server-workshop-infra." + appsHostnameSuffix,
+			Value: gogsURL,
 		},
 		{
 			Name:  "NEXUS_URL",
-			Value: "http://nexus-opentlc-shared" + appsHostnameSuffix,
+			Value: nexusURL,
+		},
+		{
+			Name:  "MAVEN_MIRROR_URL",
+			Value: mavenMirrorURL,
 		},
 		{
 			Name:  "KIALI_URL",
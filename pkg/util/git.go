@@ -0,0 +1,75 @@
+package util
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Git hosting services serving the raw content of files
+const (
+	GitHub    = "github"
+	GitLab    = "gitlab"
+	Bitbucket = "bitbucket"
+	Gitea     = "gitea"
+	Gogs      = "gogs"
+)
+
+// GetGitType guesses the hosting service of a repository from its host,
+// Gogs for a host that is not known
+func GetGitType(repositoryURL string) string {
+	gitURL, err := url.Parse(repositoryURL)
+	if err != nil {
+		return Gogs
+	}
+	switch {
+	case gitURL.Host == "github.com":
+		return GitHub
+	case gitURL.Host == "bitbucket.org":
+		return Bitbucket
+	case strings.Contains(gitURL.Host, "gitlab"):
+		return GitLab
+	default:
+		return Gogs
+	}
+}
+
+// GetRawFileURL returns the URL serving the raw content of a file of a git repository
+// hosted on GitHub, GitLab, Bitbucket, Gitea or Gogs. The service is guessed from
+// the host when gitType is empty.
+func GetRawFileURL(repositoryURL string, gitType string, branch string, path string) (string, error) {
+	gitURL, err := url.Parse(repositoryURL)
+	if err != nil {
+		return "", err
+	}
+	if gitURL.Host == "" {
+		return "", fmt.Errorf("invalid repository URL %s", repositoryURL)
+	}
+	if gitType == "" {
+		gitType = GetGitType(repositoryURL)
+	}
+
+	repositoryPath := strings.TrimSuffix(strings.Trim(gitURL.Path, "/"), ".git")
+	path = strings.TrimPrefix(path, "/")
+	scheme := gitURL.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+
+	switch strings.ToLower(gitType) {
+	case GitHub:
+		if gitURL.Host == "github.com" {
+			return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s", repositoryPath, branch, path), nil
+		}
+		// GitHub Enterprise redirects to its raw host
+		return fmt.Sprintf("%s://%s/%s/raw/%s/%s", scheme, gitURL.Host, repositoryPath, branch, path), nil
+	case GitLab:
+		return fmt.Sprintf("%s://%s/%s/-/raw/%s/%s", scheme, gitURL.Host, repositoryPath, branch, path), nil
+	case Gitea:
+		return fmt.Sprintf("%s://%s/%s/raw/branch/%s/%s", scheme, gitURL.Host, repositoryPath, branch, path), nil
+	case Bitbucket, Gogs:
+		return fmt.Sprintf("%s://%s/%s/raw/%s/%s", scheme, gitURL.Host, repositoryPath, branch, path), nil
+	default:
+		return "", fmt.Errorf("unknown git type %s, expected %s, %s, %s, %s or %s", gitType, GitHub, GitLab, Bitbucket, Gitea, Gogs)
+	}
+}
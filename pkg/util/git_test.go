@@ -0,0 +1,72 @@
+package util
+
+import "testing"
+
+func TestGetRawFileURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		repositoryURL string
+		gitType       string
+		expected      string
+	}{
+		{
+			name:          "GitHub",
+			repositoryURL: "https://github.com/RedHat-Middleware-Workshops/cloud-native-workshop-v2m1-labs.git",
+			expected:      "https://raw.githubusercontent.com/RedHat-Middleware-Workshops/cloud-native-workshop-v2m1-labs/master/devfile.yaml",
+		},
+		{
+			name:          "GitHub Enterprise",
+			repositoryURL: "https://github.example.com/workshop/labs",
+			gitType:       GitHub,
+			expected:      "https://github.example.com/workshop/labs/raw/master/devfile.yaml",
+		},
+		{
+			name:          "GitLab",
+			repositoryURL: "https://gitlab.com/workshop/labs.git",
+			expected:      "https://gitlab.com/workshop/labs/-/raw/master/devfile.yaml",
+		},
+		{
+			name:          "self-hosted GitLab",
+			repositoryURL: "https://git.example.com/workshop/labs.git",
+			gitType:       GitLab,
+			expected:      "https://git.example.com/workshop/labs/-/raw/master/devfile.yaml",
+		},
+		{
+			name:          "Gitea",
+			repositoryURL: "http://gitea-workshop-infra.apps.cluster.example.com/user1/labs.git",
+			gitType:       Gitea,
+			expected:      "http://gitea-workshop-infra.apps.cluster.example.com/user1/labs/raw/branch/master/devfile.yaml",
+		},
+		{
+			name:          "Gogs",
+			repositoryURL: "http://gogs-gogs-server-workshop-infra.apps.cluster.example.com/user1/labs.git",
+			expected:      "http://gogs-gogs-server-workshop-infra.apps.cluster.example.com/user1/labs/raw/master/devfile.yaml",
+		},
+		{
+			name:          "Bitbucket",
+			repositoryURL: "https://bitbucket.org/workshop/labs.git",
+			expected:      "https://bitbucket.org/workshop/labs/raw/master/devfile.yaml",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rawURL, err := GetRawFileURL(test.repositoryURL, test.gitType, "master", "/devfile.yaml")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if rawURL != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, rawURL)
+			}
+		})
+	}
+}
+
+func TestGetRawFileURLInvalid(t *testing.T) {
+	if _, err := GetRawFileURL("github.com/workshop/labs", "", "master", "devfile.yaml"); err == nil {
+		t.Error("expected an error for a URL without scheme")
+	}
+	if _, err := GetRawFileURL("https://git.example.com/workshop/labs", "svn", "master", "devfile.yaml"); err == nil {
+		t.Error("expected an error for an unknown git type")
+	}
+}